@@ -0,0 +1,43 @@
+package sloglog
+
+import "log/slog"
+
+// badKey is the key of values in args that are not preceded by a key, as in log/slog
+const badKey = "!BADKEY"
+
+// KeyValueArgs converts log arguments to attributes following the log/slog convention:
+// an slog.Attr is used as is, a string is a key followed by its value, e.g.
+// KeyValueArgs("user", "alice", slog.Int("attempt", 2), "status", 200), and any other value,
+// or a string without a value, is recorded under the key "!BADKEY".
+// Values implementing slog.LogValuer are resolved
+func KeyValueArgs(args ...any) []slog.Attr {
+	return appendKeyValueArgs(make([]slog.Attr, 0, len(args)), args...)
+}
+
+// appendKeyValueArgs appends the attributes of args, converted as described for KeyValueArgs,
+// to attrs. Only values of kind slog.KindLogValuer are resolved, so that other values are not
+// boxed or copied
+func appendKeyValueArgs(attrs []slog.Attr, args ...any) []slog.Attr {
+	for i := 0; i < len(args); i++ {
+		var attr slog.Attr
+		switch arg := args[i].(type) {
+		case slog.Attr:
+			attr = arg
+		case string:
+			if i+1 < len(args) {
+				attr = slog.Any(arg, args[i+1])
+				i++
+			} else {
+				attr = slog.String(badKey, arg)
+			}
+		default:
+			attr = slog.Any(badKey, arg)
+		}
+
+		if attr.Value.Kind() == slog.KindLogValuer {
+			attr.Value = attr.Value.Resolve()
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs
+}
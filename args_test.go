@@ -0,0 +1,65 @@
+package sloglog
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// account is a LogValuer logging only its ID, never the secret
+type account struct {
+	id     string
+	secret string
+}
+
+func (a account) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("id", a.id))
+}
+
+func TestKeyValueArgs(t *testing.T) {
+	attrs := KeyValueArgs("user", "alice", slog.Int("attempt", 2), "status", 200, 3.5, "dangling")
+
+	want := []string{"user=alice", "attempt=2", "status=200", "!BADKEY=3.5", "!BADKEY=dangling"}
+	if len(attrs) != len(want) {
+		t.Fatalf("KeyValueArgs returned %d attributes %v, want %v", len(attrs), attrs, want)
+	}
+	for i, a := range attrs {
+		if got := a.String(); got != want[i] {
+			t.Errorf("attribute %d = %s, want %s", i, got, want[i])
+		}
+	}
+}
+
+func TestKeyValueArgsResolvesLogValuer(t *testing.T) {
+	attrs := KeyValueArgs("account", account{id: "a-1", secret: "s3cret"})
+
+	if len(attrs) != 1 || attrs[0].Value.Kind() != slog.KindGroup {
+		t.Fatalf("attributes = %v, want the resolved group", attrs)
+	}
+	if group := attrs[0].Value.Group(); len(group) != 1 || group[0].String() != "id=a-1" {
+		t.Errorf("resolved value = %v, want [id=a-1]", group)
+	}
+}
+
+func TestLogResolvesLogValuer(t *testing.T) {
+	logger, buf := newBufferLogger(WithSource(false))
+
+	logger.Info("login", "account", account{id: "a-1", secret: "s3cret"})
+
+	out := buf.String()
+	if !strings.Contains(out, "account.id=a-1") || strings.Contains(out, "s3cret") {
+		t.Errorf("output %q does not carry the resolved LogValue only", out)
+	}
+}
+
+func TestAppendKeyValueArgsAllocs(t *testing.T) {
+	args := []any{slog.Int("attempt", 2), "user", "alice"}
+	attrs := make([]slog.Attr, 0, len(args))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		attrs = appendKeyValueArgs(attrs[:0], args...)
+	})
+	if allocs != 0 {
+		t.Errorf("appendKeyValueArgs allocated %v times, want 0", allocs)
+	}
+}
@@ -0,0 +1,96 @@
+package sloglog
+
+// asyncEntry is a formatted entry queued for the background writer. An entry with a non-nil
+// flushed channel is a marker that is acknowledged once all entries queued before it are written
+type asyncEntry struct {
+	entry   string
+	flushed chan struct{}
+}
+
+// WithAsyncWriter hands formatted entries to a background goroutine through a channel holding
+// up to bufferSize entries, so that logging does not wait for the file. When the channel is full,
+// entries are dropped and counted in FileLoggerStats.DroppedRecords if dropOnFull is set,
+// otherwise the logging goroutine blocks until there is room
+func WithAsyncWriter(bufferSize int, dropOnFull bool) FileLoggerOption {
+	return func(o *FileLoggerOptions) {
+		o.AsyncBufferSize = bufferSize
+		o.DropOnFull = dropOnFull
+	}
+}
+
+// enable enables the file logger, starting the background writer if it is asynchronous
+func (fl *FileLogger) enable() {
+	fl.indexed.Do(fl.loadIndex)
+
+	fl.asyncMu.Lock()
+	defer fl.asyncMu.Unlock()
+
+	if fl.opts.AsyncBufferSize > 0 && fl.async == nil {
+		fl.async = make(chan asyncEntry, fl.opts.AsyncBufferSize)
+		fl.asyncDone = make(chan struct{})
+		go fl.asyncLoop(fl.async, fl.asyncDone)
+	}
+	fl.enabled.Store(true)
+}
+
+// asyncLoop writes queued entries until ch is closed
+func (fl *FileLogger) asyncLoop(ch <-chan asyncEntry, done chan<- struct{}) {
+	defer close(done)
+
+	for e := range ch {
+		if e.flushed != nil {
+			close(e.flushed)
+			continue
+		}
+		fl.writeEntry(e.entry)
+	}
+}
+
+// writeAsync queues entry for the background writer, writing it directly if the writer has
+// already been stopped by Close
+func (fl *FileLogger) writeAsync(entry string) {
+	fl.asyncMu.RLock()
+	defer fl.asyncMu.RUnlock()
+
+	if fl.async == nil {
+		fl.writeEntry(entry)
+		return
+	}
+
+	if !fl.opts.DropOnFull {
+		fl.async <- asyncEntry{entry: entry}
+		return
+	}
+	select {
+	case fl.async <- asyncEntry{entry: entry}:
+	default:
+		fl.dropped.Add(1)
+	}
+}
+
+// waitAsync waits until the entries queued so far have been written
+func (fl *FileLogger) waitAsync() {
+	fl.asyncMu.RLock()
+	defer fl.asyncMu.RUnlock()
+
+	if fl.async == nil {
+		return
+	}
+	flushed := make(chan struct{})
+	fl.async <- asyncEntry{flushed: flushed}
+	<-flushed
+}
+
+// stopAsync stops the background writer after it has written all queued entries
+func (fl *FileLogger) stopAsync() {
+	fl.asyncMu.Lock()
+	defer fl.asyncMu.Unlock()
+
+	if fl.async == nil {
+		return
+	}
+	close(fl.async)
+	<-fl.asyncDone
+	fl.async = nil
+	fl.asyncDone = nil
+}
@@ -0,0 +1,102 @@
+package sloglog
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countFileLines returns the number of lines in the log files in dir
+func countFileLines(t *testing.T, dir string) int {
+	t.Helper()
+	return strings.Count(readDirLogs(t, dir), "\n")
+}
+
+func TestAsyncWriterNoLoss(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)}
+	fl := newTestFileLogger(t, FileLoggerOptions{AsyncBufferSize: 8}, clock)
+
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 250 {
+				fl.writeToFile(fmt.Sprintf("goroutine %d record %d", g, i))
+			}
+		}()
+	}
+	wg.Wait()
+	if err := fl.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := countFileLines(t, fl.opts.Dir); n != 1000 {
+		t.Errorf("file has %d records, want all 1000", n)
+	}
+	if dropped := fl.Stats().DroppedRecords; dropped != 0 {
+		t.Errorf("DroppedRecords = %d, want 0 when blocking", dropped)
+	}
+}
+
+func TestAsyncWriterDropOnFull(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)}
+	fl := newTestFileLogger(t, FileLoggerOptions{AsyncBufferSize: 4, DropOnFull: true}, clock)
+
+	// Stall the background writer so that the queue fills up
+	fl.mu.Lock()
+	for i := range 20 {
+		fl.writeToFile(fmt.Sprintf("record %d", i))
+	}
+	fl.mu.Unlock()
+	fl.Close()
+
+	dropped := fl.Stats().DroppedRecords
+	if dropped == 0 {
+		t.Fatal("no records were dropped although the queue was full")
+	}
+	if written := countFileLines(t, fl.opts.Dir); int64(written)+dropped != 20 {
+		t.Errorf("%d records written and %d dropped, want 20 in total", written, dropped)
+	}
+}
+
+func TestDisableFileLoggingDrainsAsyncQueue(t *testing.T) {
+	dir := enableTestFileLogging(t, FileLoggerOptions{AsyncBufferSize: 1024})
+	logger, _ := newBufferLogger()
+
+	for i := range 100 {
+		logger.Info("queued", slog.Int("i", i))
+	}
+	DisableFileLogging()
+
+	if n := strings.Count(readDirLogs(t, dir), "queued"); n != 100 {
+		t.Errorf("file has %d records after DisableFileLogging, want 100", n)
+	}
+}
+
+func BenchmarkFileLoggerAsync(b *testing.B) {
+	benchmarkFileLogger(b, FileLoggerOptions{AsyncBufferSize: 8192})
+}
+
+// BenchmarkAsyncWriterLatency reports the 99th percentile of the time the logging goroutine
+// spends handing an entry to the background writer
+func BenchmarkAsyncWriterLatency(b *testing.B) {
+	fl := newFileLogger(FileLoggerOptions{Dir: b.TempDir(), AsyncBufferSize: 8192})
+	fl.enable()
+	defer fl.Close()
+
+	entry := "[2026-01-02 15:04:05.000] INFO  | order matched\n  └─ price: 101.25"
+	var latencies []time.Duration
+	for b.Loop() {
+		start := time.Now()
+		fl.writeToFile(entry)
+		latencies = append(latencies, time.Since(start))
+	}
+
+	slices.Sort(latencies)
+	b.ReportMetric(float64(latencies[len(latencies)*99/100].Nanoseconds()), "p99-ns")
+}
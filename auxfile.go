@@ -0,0 +1,94 @@
+package sloglog
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// auxFile is an additional file receiving the records tagged with tag
+type auxFile struct {
+	tag string
+	fl  *FileLogger
+}
+
+// WithAuxFile returns a child logger that also writes every record carrying slog.Bool(tag, true),
+// e.g. slog.Bool("audit", true), to a separate file logger in dir. All records still go to the
+// primary outputs. Calls can be chained to route different tags to different files.
+// The Dir field of opts is replaced by dir, and the index is kept in <tag>_index.json unless
+// opts sets IndexFile
+func (l *Logger) WithAuxFile(tag string, dir string, opts FileLoggerOptions) *Logger {
+	opts.Dir = dir
+	if opts.IndexFile == "" {
+		opts.IndexFile = tag + "_" + defaultIndexFile
+	}
+	fl := newFileLogger(opts)
+	fl.enable()
+
+	c := l.clone()
+	c.auxFiles = append(l.auxFiles[:len(l.auxFiles):len(l.auxFiles)], auxFile{tag: tag, fl: fl})
+	return c
+}
+
+// CloseAuxFiles flushes and closes the files added by WithAuxFile. The files are shared with
+// the loggers derived from l, which stop writing to them
+func (l *Logger) CloseAuxFiles() error {
+	var errs []error
+	for _, aux := range l.auxFiles {
+		errs = append(errs, aux.fl.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// writeAuxFiles writes record, with the top-level attributes top, to the aux files whose tag
+// it carries
+func (l *Logger) writeAuxFiles(top []slog.Attr, record slog.Record) {
+	if len(l.auxFiles) == 0 {
+		return
+	}
+
+	var entry string
+	for _, aux := range l.auxFiles {
+		if !l.hasTag(record, aux.tag) {
+			continue
+		}
+		if entry == "" {
+			entry = l.formatLogEntry(top, record)
+		}
+		aux.fl.writeToFile(entry)
+	}
+}
+
+// flushAuxFiles flushes buffered entries of the aux files, e.g. before the process exits
+func (l *Logger) flushAuxFiles() {
+	for _, aux := range l.auxFiles {
+		aux.fl.Flush()
+	}
+}
+
+// hasTag reports whether record, or the attributes pre-set on l with With outside of any
+// group, have a boolean attribute tag set to true
+func (l *Logger) hasTag(record slog.Record, tag string) bool {
+	if len(l.attrs) > 0 {
+		for _, a := range l.attrs[0] {
+			if isTag(a, tag) {
+				return true
+			}
+		}
+	}
+
+	found := false
+	record.Attrs(func(a slog.Attr) bool {
+		if isTag(a, tag) {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// isTag reports whether a is the boolean attribute tag set to true
+func isTag(a slog.Attr, tag string) bool {
+	v := a.Value.Resolve()
+	return a.Key == tag && v.Kind() == slog.KindBool && v.Bool()
+}
@@ -0,0 +1,71 @@
+package sloglog
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readDirLogs returns the contents of the log files in dir
+func readDirLogs(t *testing.T, dir string) string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sb.Write(data)
+	}
+	return sb.String()
+}
+
+func TestWithAuxFile(t *testing.T) {
+	auditDir, securityDir := t.TempDir(), t.TempDir()
+	logger, buf := newBufferLogger()
+	logger = logger.
+		WithAuxFile("audit", auditDir, FileLoggerOptions{}).
+		WithAuxFile("security", securityDir, FileLoggerOptions{})
+
+	logger.Info("role granted", slog.Bool("audit", true))
+	logger.Info("cache warmed")
+	logger.Info("login failed", slog.Bool("security", true))
+	logger.Info("not audited", slog.Bool("audit", false))
+	logger.Info("both", slog.Bool("audit", true), slog.Bool("security", true))
+	logger.With(slog.Bool("audit", true)).Info("password changed")
+	if err := logger.CloseAuxFiles(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dir     string
+		want    []string
+		notWant []string
+	}{
+		{auditDir, []string{"role granted", "both", "password changed"}, []string{"cache warmed", "login failed", "not audited"}},
+		{securityDir, []string{"login failed", "both"}, []string{"role granted", "cache warmed", "password changed"}},
+	}
+	for _, tt := range tests {
+		got := readDirLogs(t, tt.dir)
+		for _, msg := range tt.want {
+			if !strings.Contains(got, msg) {
+				t.Errorf("aux file in %s is missing %q:\n%s", tt.dir, msg, got)
+			}
+		}
+		for _, msg := range tt.notWant {
+			if strings.Contains(got, msg) {
+				t.Errorf("aux file in %s contains %q:\n%s", tt.dir, msg, got)
+			}
+		}
+	}
+
+	if n := len(lines(buf)); n != 6 {
+		t.Errorf("console received %d records, want all 6", n)
+	}
+}
@@ -0,0 +1,64 @@
+package sloglog
+
+import "log/slog"
+
+// colorReset ends an ANSI color sequence
+const colorReset = "\033[0m"
+
+// ColorScheme holds the ANSI escape sequences that prefix the level tag of each level in
+// colored console output. PANIC and FATAL use the Error color. An empty sequence leaves the
+// level uncolored
+type ColorScheme struct {
+	Debug string
+	Info  string
+	Warn  string
+	Error string
+}
+
+// DefaultColorScheme returns the default colors: gray DEBUG, blue INFO, yellow WARN and red ERROR
+func DefaultColorScheme() ColorScheme {
+	return ColorScheme{
+		Debug: "\033[37m",
+		Info:  "\033[34m",
+		Warn:  "\033[33m",
+		Error: "\033[31m",
+	}
+}
+
+// NoColorScheme returns a scheme that leaves all levels uncolored
+func NoColorScheme() ColorScheme {
+	return ColorScheme{}
+}
+
+// WideColorScheme returns a high-contrast scheme: dim DEBUG, green INFO, bright yellow WARN
+// and bold red ERROR
+func WideColorScheme() ColorScheme {
+	return ColorScheme{
+		Debug: "\033[90m",
+		Info:  "\033[32m",
+		Warn:  "\033[93m",
+		Error: "\033[1;31m",
+	}
+}
+
+// formatLevel formats the level tag with the color of the level
+func (cs ColorScheme) formatLevel(level slog.Level) string {
+	tag := "[" + formatLevel(level) + "]"
+
+	var color string
+	switch level {
+	case slog.LevelDebug:
+		color = cs.Debug
+	case slog.LevelInfo:
+		color = cs.Info
+	case slog.LevelWarn:
+		color = cs.Warn
+	case slog.LevelError, LevelPanic, LevelFatal:
+		color = cs.Error
+	}
+
+	if color == "" {
+		return tag
+	}
+	return color + tag + colorReset
+}
@@ -0,0 +1,79 @@
+package sloglog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNoColorForNonTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(WithWriter(&buf))
+
+	logger.Info("plain")
+	logger.Error("plain")
+
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("output to a buffer contains ANSI sequences: %q", buf.String())
+	}
+}
+
+func TestWithColor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(WithWriter(&buf), WithColor(true))
+
+	logger.Info("colored")
+
+	if !strings.Contains(buf.String(), "\033[") {
+		t.Errorf("output with forced colors has no ANSI sequences: %q", buf.String())
+	}
+}
+
+func TestMinLoggerColorless(t *testing.T) {
+	h, ok := Min.logger.Handler().(*CustomHandler)
+	if !ok {
+		t.Fatalf("Min has handler %T, want *CustomHandler", Min.logger.Handler())
+	}
+	if h.color {
+		t.Error("Min has colors enabled by default")
+	}
+}
+
+func TestWithColorScheme(t *testing.T) {
+	scheme := ColorScheme{Debug: "\033[36m", Info: "\033[32m", Warn: "\033[35m", Error: "\033[41m"}
+	var buf bytes.Buffer
+	logger := NewLogger(WithWriter(&buf), WithColor(true), WithColorScheme(scheme), WithLevel(slog.LevelDebug))
+
+	tests := []struct {
+		log  func(msg string, args ...any)
+		want string
+	}{
+		{logger.Debug, "\033[36m[DEBUG]" + colorReset},
+		{logger.Info, "\033[32m[INFO]" + colorReset},
+		{logger.Warn, "\033[35m[WARN]" + colorReset},
+		{logger.Error, "\033[41m[ERROR]" + colorReset},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		tt.log("message")
+		if !strings.Contains(buf.String(), tt.want+" message") {
+			t.Errorf("output %q does not contain %q before the message", buf.String(), tt.want)
+		}
+	}
+}
+
+func TestColorSchemes(t *testing.T) {
+	if got := NoColorScheme().formatLevel(slog.LevelWarn); got != "[WARN]" {
+		t.Errorf("NoColorScheme level tag = %q, want [WARN] without escapes", got)
+	}
+	if got, want := DefaultColorScheme().formatLevel(LevelFatal), "\033[31m[FATAL]"+colorReset; got != want {
+		t.Errorf("FATAL level tag = %q, want the Error color %q", got, want)
+	}
+
+	wide := WideColorScheme()
+	colors := map[string]bool{wide.Debug: true, wide.Info: true, wide.Warn: true, wide.Error: true}
+	if len(colors) != 4 || colors[""] {
+		t.Errorf("WideColorScheme = %+v, want four distinct colors", wide)
+	}
+}
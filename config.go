@@ -0,0 +1,230 @@
+package sloglog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes a logger in a form that can be loaded from JSON or YAML
+type Config struct {
+	// Level is a level name or integer accepted by ParseLevel (default: "INFO")
+	Level string `json:"level,omitempty" yaml:"level,omitempty"`
+
+	// Format is "text" or "json" (default: "text")
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+
+	// AddSource enables source location tracking (default: true)
+	AddSource *bool `json:"add_source,omitempty" yaml:"add_source,omitempty"`
+
+	// Color forces ANSI colors on or off (default: auto-detect)
+	Color *bool `json:"color,omitempty" yaml:"color,omitempty"`
+
+	// ErrorKey is the attribute key used for errors (default: "error")
+	ErrorKey string `json:"error_key,omitempty" yaml:"error_key,omitempty"`
+
+	// TraceIDKey is the attribute key of trace IDs taken from the context (default: "trace_id")
+	TraceIDKey string `json:"trace_id_key,omitempty" yaml:"trace_id_key,omitempty"`
+
+	// CallerSkip is the number of additional stack frames to skip for source attribution
+	CallerSkip int `json:"caller_skip,omitempty" yaml:"caller_skip,omitempty"`
+
+	FileLogging FileLoggingConfig `json:"file_logging" yaml:"file_logging"`
+}
+
+// FileLoggingConfig describes file logging, mirroring FileLoggerOptions
+type FileLoggingConfig struct {
+	Enabled          bool   `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Dir              string `json:"dir,omitempty" yaml:"dir,omitempty"`
+	Rotation         string `json:"rotation,omitempty" yaml:"rotation,omitempty"`
+	FilenameTemplate string `json:"filename_template,omitempty" yaml:"filename_template,omitempty"`
+	MaxAgeDays       int    `json:"max_age_days,omitempty" yaml:"max_age_days,omitempty"`
+	MaxCount         int    `json:"max_count,omitempty" yaml:"max_count,omitempty"`
+	MaxSizeMB        int    `json:"max_size_mb,omitempty" yaml:"max_size_mb,omitempty"`
+	Compress         bool   `json:"compress,omitempty" yaml:"compress,omitempty"`
+	BufferSize       int    `json:"buffer_size,omitempty" yaml:"buffer_size,omitempty"`
+	FlushIntervalMs  int    `json:"flush_interval_ms,omitempty" yaml:"flush_interval_ms,omitempty"`
+	AsyncBufferSize  int    `json:"async_buffer_size,omitempty" yaml:"async_buffer_size,omitempty"`
+	DropOnFull       bool   `json:"drop_on_full,omitempty" yaml:"drop_on_full,omitempty"`
+}
+
+// LoadConfig decodes a Config from r in the given format ("json", "yaml" or "yml") and validates it
+func LoadConfig(r io.Reader, format string) (*Config, error) {
+	cfg := &Config{}
+
+	switch strings.ToLower(format) {
+	case "json":
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to decode JSON config: %w", err)
+		}
+	case "yaml", "yml":
+		dec := yaml.NewDecoder(r)
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to decode YAML config: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", format)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadConfigFromFile loads a Config from path, choosing the format from the file extension
+func LoadConfigFromFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	format := strings.TrimPrefix(filepath.Ext(path), ".")
+	return LoadConfig(bytes.NewReader(data), format)
+}
+
+// Validate reports the first invalid field of the config
+func (c *Config) Validate() error {
+	if c.Level != "" {
+		if _, err := ParseLevel(c.Level); err != nil {
+			return fmt.Errorf("invalid level: %w", err)
+		}
+	}
+	if _, err := parseFormat(c.Format); err != nil {
+		return err
+	}
+	if c.CallerSkip < 0 {
+		return fmt.Errorf("caller_skip must not be negative, got %d", c.CallerSkip)
+	}
+
+	fc := c.FileLogging
+	if fc.Enabled && fc.Dir == "" {
+		return errors.New("file_logging.dir must be set when file logging is enabled")
+	}
+	if _, err := parseRotationSchedule(fc.Rotation); err != nil {
+		return err
+	}
+	if fc.MaxAgeDays < 0 || fc.MaxCount < 0 || fc.MaxSizeMB < 0 || fc.BufferSize < 0 || fc.FlushIntervalMs < 0 || fc.AsyncBufferSize < 0 {
+		return errors.New("file_logging values must not be negative")
+	}
+	return nil
+}
+
+// level returns the configured level, defaulting to INFO
+func (c *Config) level() slog.Level {
+	if c.Level == "" {
+		return slog.LevelInfo
+	}
+	level, _ := ParseLevel(c.Level)
+	return level
+}
+
+// options converts the config, apart from its level, to Options
+func (c *Config) options() []Option {
+	format, _ := parseFormat(c.Format)
+	opts := []Option{WithFormat(format), WithCallerSkipOption(c.CallerSkip)}
+	if c.AddSource != nil {
+		opts = append(opts, WithSource(*c.AddSource))
+	}
+	if c.Color != nil {
+		opts = append(opts, WithColor(*c.Color))
+	}
+	if c.ErrorKey != "" {
+		opts = append(opts, WithErrorKey(c.ErrorKey))
+	}
+	if c.TraceIDKey != "" {
+		opts = append(opts, WithTraceIDKey(c.TraceIDKey))
+	}
+	return opts
+}
+
+// fileLoggerOptions converts the file logging config to FileLoggerOptions
+func (fc FileLoggingConfig) fileLoggerOptions() FileLoggerOptions {
+	schedule, _ := parseRotationSchedule(fc.Rotation)
+	return FileLoggerOptions{
+		Dir:              fc.Dir,
+		Schedule:         schedule,
+		FilenameTemplate: fc.FilenameTemplate,
+		MaxAge:           time.Duration(fc.MaxAgeDays) * 24 * time.Hour,
+		MaxCount:         fc.MaxCount,
+		MaxSize:          int64(fc.MaxSizeMB) << 20,
+		Compress:         fc.Compress,
+		BufferSize:       fc.BufferSize,
+		FlushInterval:    time.Duration(fc.FlushIntervalMs) * time.Millisecond,
+		AsyncBufferSize:  fc.AsyncBufferSize,
+		DropOnFull:       fc.DropOnFull,
+	}
+}
+
+// parseFormat parses a format name
+func parseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return FormatText, fmt.Errorf("invalid format %q, expected \"text\" or \"json\"", s)
+	}
+}
+
+// parseRotationSchedule parses a rotation schedule name
+func parseRotationSchedule(s string) (RotationSchedule, error) {
+	switch strings.ToLower(s) {
+	case "", "daily":
+		return RotateDaily, nil
+	case "hourly":
+		return RotateHourly, nil
+	case "weekly":
+		return RotateWeekly, nil
+	case "monthly":
+		return RotateMonthly, nil
+	default:
+		return RotateDaily, fmt.Errorf("invalid file_logging.rotation %q, expected hourly, daily, weekly or monthly", s)
+	}
+}
+
+// NewLoggerFromConfig creates a logger from cfg. When file logging is enabled in cfg the
+// package-wide file logger is configured as well
+func NewLoggerFromConfig(cfg *Config) (*Logger, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	logger := NewLogger(append([]Option{WithLevel(cfg.level())}, cfg.options()...)...)
+	if cfg.FileLogging.Enabled {
+		EnableFileLoggingWithOptions(cfg.FileLogging.fileLoggerOptions())
+	}
+	return logger, nil
+}
+
+// InitLoggerFromEnv initializes the package-level loggers from the config file named by the
+// LOG_CONFIG_PATH environment variable. It does nothing when the variable is not set
+func InitLoggerFromEnv() error {
+	path := os.Getenv("LOG_CONFIG_PATH")
+	if path == "" {
+		return nil
+	}
+
+	cfg, err := LoadConfigFromFile(path)
+	if err != nil {
+		return err
+	}
+
+	InitLogger(cfg.level(), cfg.options()...)
+	if cfg.FileLogging.Enabled {
+		EnableFileLoggingWithOptions(cfg.FileLogging.fileLoggerOptions())
+	}
+	return nil
+}
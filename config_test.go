@@ -0,0 +1,159 @@
+package sloglog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fullConfig returns a config with every field set
+func fullConfig() *Config {
+	addSource, color := false, true
+	return &Config{
+		Level:      "debug",
+		Format:     "json",
+		AddSource:  &addSource,
+		Color:      &color,
+		ErrorKey:   "err",
+		TraceIDKey: "traceId",
+		CallerSkip: 2,
+		FileLogging: FileLoggingConfig{
+			Enabled:          true,
+			Dir:              "/var/log/app",
+			Rotation:         "hourly",
+			FilenameTemplate: "app_2006-01-02_15",
+			MaxAgeDays:       7,
+			MaxCount:         48,
+			MaxSizeMB:        100,
+			Compress:         true,
+			BufferSize:       65536,
+			FlushIntervalMs:  500,
+			AsyncBufferSize:  8192,
+			DropOnFull:       true,
+		},
+	}
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	tests := []struct {
+		format  string
+		marshal func(any) ([]byte, error)
+	}{
+		{"json", json.Marshal},
+		{"yaml", yaml.Marshal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			want := fullConfig()
+			data, err := tt.marshal(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := LoadConfig(bytes.NewReader(data), tt.format)
+			if err != nil {
+				t.Fatalf("LoadConfig(%s) failed: %v", data, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip of %s = %+v, want %+v", data, got, want)
+			}
+		})
+	}
+}
+
+func TestConfigJSONKeepsFileLogging(t *testing.T) {
+	data, err := json.Marshal(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"file_logging"`) {
+		t.Errorf("marshaled empty config %s has no file_logging object", data)
+	}
+}
+
+func TestLoadConfigFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logging.yml")
+	content := "level: warn\nfile_logging:\n  max_size_mb: 10\n  compress: true\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfigFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "warn" || cfg.FileLogging.MaxSizeMB != 10 || !cfg.FileLogging.Compress {
+		t.Errorf("config = %+v, want level warn, max_size_mb 10 and compress", cfg)
+	}
+	if opts := cfg.FileLogging.fileLoggerOptions(); opts.MaxSize != 10<<20 || !opts.Compress {
+		t.Errorf("file logger options = %+v, want MaxSize 10 MiB and Compress", opts)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		input   string
+		wantErr string
+	}{
+		{"unknown level", "json", `{"level":"loud"}`, "invalid level"},
+		{"unknown format", "json", `{"format":"xml"}`, "invalid format"},
+		{"negative caller skip", "json", `{"caller_skip":-1}`, "caller_skip"},
+		{"missing dir", "yaml", "file_logging:\n  enabled: true\n", "file_logging.dir"},
+		{"unknown rotation", "yaml", "file_logging:\n  rotation: yearly\n", "file_logging.rotation"},
+		{"negative size", "json", `{"file_logging":{"max_size_mb":-1}}`, "must not be negative"},
+		{"unknown field", "json", `{"max_size":1}`, "unknown field"},
+		{"unknown YAML field", "yaml", "tracing: true\n", "not found"},
+		{"unsupported format", "toml", "", "unsupported config format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfig(strings.NewReader(tt.input), tt.format)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig(%q) error = %v, want one containing %q", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigTraceIDKey(t *testing.T) {
+	cfg := &Config{TraceIDKey: "traceId", Format: "json"}
+	var buf bytes.Buffer
+	logger := NewLogger(append(cfg.options(), WithWriter(&buf), WithColor(false))...)
+
+	logger.InfoCtx(context.WithValue(context.Background(), TraceIDContextKey, "trace-1"), "request")
+
+	records := decodeJSONLines(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if records[0]["traceId"] != "trace-1" || records[0]["trace_id"] != nil {
+		t.Errorf("record = %v, want the trace ID under traceId only", records[0])
+	}
+}
+
+func TestNewLoggerFromConfig(t *testing.T) {
+	cfg := &Config{Level: "warn", Format: "json", ErrorKey: "err"}
+	logger, err := NewLoggerFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logger.Enabled(t.Context(), slog.LevelInfo) {
+		t.Error("logger from a WARN config enables INFO")
+	}
+
+	if _, err := NewLoggerFromConfig(&Config{Level: "loud"}); err == nil {
+		t.Error("NewLoggerFromConfig accepted an invalid level")
+	}
+}
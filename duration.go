@@ -0,0 +1,71 @@
+package sloglog
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DurationAttr returns an attribute holding d in a human-readable form with the unit chosen by
+// magnitude: nanoseconds below 1µs, microseconds below 1ms, milliseconds below 1s and seconds
+// otherwise, e.g. latency=1.23ms. A zero duration is written as 0s
+func DurationAttr(key string, d time.Duration) slog.Attr {
+	return slog.String(key, formatDuration(d))
+}
+
+// DurationAttrMillis returns an attribute holding d as fractional milliseconds
+func DurationAttrMillis(key string, d time.Duration) slog.Attr {
+	return slog.Float64(key, float64(d)/float64(time.Millisecond))
+}
+
+// TimeSinceAttr returns a DurationAttr holding the time elapsed since start
+func TimeSinceAttr(key string, start time.Time) slog.Attr {
+	return DurationAttr(key, time.Since(start))
+}
+
+// roundingFor returns the precision of two decimals in the unit used for d
+func roundingFor(d time.Duration) time.Duration {
+	switch {
+	case d < time.Microsecond:
+		return 1
+	case d < time.Millisecond:
+		return 10 * time.Nanosecond
+	case d < time.Second:
+		return 10 * time.Microsecond
+	default:
+		return 10 * time.Millisecond
+	}
+}
+
+// formatDuration formats d with at most two decimals in the largest unit not exceeding it
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+
+	// Round to the precision written first, so 999.999ms becomes 1s rather than 1000ms
+	abs = abs.Round(roundingFor(abs))
+
+	var unit time.Duration
+	var suffix string
+	switch {
+	case abs < time.Microsecond:
+		return strconv.FormatInt(int64(d), 10) + "ns"
+	case abs < time.Millisecond:
+		unit, suffix = time.Microsecond, "µs"
+	case abs < time.Second:
+		unit, suffix = time.Millisecond, "ms"
+	default:
+		unit, suffix = time.Second, "s"
+	}
+
+	s := strconv.FormatFloat(float64(d)/float64(unit), 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	return s + suffix
+}
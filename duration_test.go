@@ -0,0 +1,53 @@
+package sloglog
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDurationAttr(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{999 * time.Nanosecond, "999ns"},
+		{1500 * time.Nanosecond, "1.5µs"},
+		{1234567 * time.Nanosecond, "1.23ms"},
+		{999999 * time.Microsecond, "1s"},
+		{90 * time.Second, "90s"},
+		{-2500 * time.Microsecond, "-2.5ms"},
+	}
+
+	for _, tt := range tests {
+		a := DurationAttr("latency", tt.d)
+		if a.Key != "latency" || a.Value.String() != tt.want {
+			t.Errorf("DurationAttr(%v) = %s=%s, want latency=%s", tt.d, a.Key, a.Value, tt.want)
+		}
+	}
+}
+
+func TestDurationAttrMillis(t *testing.T) {
+	a := DurationAttrMillis("latency", 1500*time.Microsecond)
+	if a.Value.Kind() != slog.KindFloat64 || a.Value.Float64() != 1.5 {
+		t.Errorf("DurationAttrMillis = %v, want 1.5", a.Value)
+	}
+}
+
+func TestTimeSinceAttr(t *testing.T) {
+	a := TimeSinceAttr("elapsed", time.Now().Add(-2*time.Second))
+	if !strings.HasSuffix(a.Value.String(), "s") || strings.HasSuffix(a.Value.String(), "ms") {
+		t.Errorf("TimeSinceAttr = %v, want seconds", a.Value)
+	}
+}
+
+func TestDurationAttrInOutput(t *testing.T) {
+	logger, buf := newBufferLogger(WithSource(false))
+	logger.Info("request", DurationAttr("latency", 1234567*time.Nanosecond))
+
+	if !strings.Contains(buf.String(), "latency=1.23ms") {
+		t.Errorf("output %q does not contain latency=1.23ms", buf)
+	}
+}
@@ -6,8 +6,16 @@ require github.com/valyala/fasthttp v1.62.0
 
 require github.com/google/uuid v1.4.0
 
+require golang.org/x/term v0.32.0
+
+require go.opentelemetry.io/otel/trace v1.38.0
+
+require gopkg.in/yaml.v3 v3.0.1
+
 require (
 	github.com/andybalholm/brotli v1.2.0 // indirect
 	github.com/klauspost/compress v1.18.0 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	go.opentelemetry.io/otel v1.38.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
 )
@@ -0,0 +1,82 @@
+package sloglog
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"unsafe"
+)
+
+// goidOffset returns the offset of the ID field in the runtime's goroutine struct, or -1 when
+// it could not be determined and goroutineID falls back to stackGoroutineID. It is calibrated
+// on the first use
+var goidOffset = sync.OnceValue(calibrateGoidOffset)
+
+// goroutineID returns the ID of the calling goroutine. Where getg is implemented it reads the
+// ID from the goroutine struct at goidOffset, which costs a few nanoseconds instead of the
+// microseconds of formatting a stack header
+func goroutineID() uint64 {
+	offset := goidOffset()
+	if offset < 0 {
+		return stackGoroutineID()
+	}
+	return *(*uint64)(unsafe.Add(getg(), offset))
+}
+
+// stackGoroutineID parses the ID of the calling goroutine from the header line of its stack,
+// "goroutine 123 [running]:". Only the header is captured to keep the cost low
+func stackGoroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	b := bytes.TrimPrefix(buf[:n], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// goidScanSize is the number of bytes at the start of the goroutine struct searched for the
+// ID. The field lies well within it in all Go versions so far
+const goidScanSize = 256
+
+// calibrateGoidOffset finds the offset of the goroutine ID as the one word of the goroutine
+// struct that equals the ID in the stack header in each of several goroutines. It returns -1
+// when getg is not implemented or the offset is ambiguous
+func calibrateGoidOffset() int {
+	if getg() == nil {
+		return -1
+	}
+
+	const goroutines = 4
+	matches := make([]int, goidScanSize/8)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g, id := getg(), stackGoroutineID()
+			mu.Lock()
+			defer mu.Unlock()
+			for i := range matches {
+				if *(*uint64)(unsafe.Add(g, i*8)) == id {
+					matches[i]++
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	offset := -1
+	for i, n := range matches {
+		if n == goroutines {
+			if offset >= 0 {
+				return -1
+			}
+			offset = i * 8
+		}
+	}
+	return offset
+}
@@ -0,0 +1,8 @@
+//go:build amd64 || arm64
+
+package sloglog
+
+import "unsafe"
+
+// getg returns the runtime's struct of the calling goroutine, implemented in assembly
+func getg() unsafe.Pointer
@@ -0,0 +1,11 @@
+//go:build !amd64 && !arm64
+
+package sloglog
+
+import "unsafe"
+
+// getg returns nil on architectures without an assembly implementation, making goroutineID
+// parse the stack header
+func getg() unsafe.Pointer {
+	return nil
+}
@@ -0,0 +1,81 @@
+package sloglog
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// RequestAttrs returns the standard access-log request attributes of a fasthttp request:
+// method, path, query, remote_ip, user_agent, request_size and, when set, trace_id
+func RequestAttrs(ctx *fasthttp.RequestCtx) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("method", string(ctx.Method())),
+		slog.String("path", string(ctx.Path())),
+		slog.String("query", string(ctx.QueryArgs().QueryString())),
+		slog.String("remote_ip", ctx.RemoteIP().String()),
+		slog.String("user_agent", string(ctx.UserAgent())),
+		slog.Int("request_size", len(ctx.Request.Body())),
+	}
+	return appendTraceIDAttr(attrs, GetTraceID(ctx))
+}
+
+// ResponseAttrs returns the standard access-log response attributes of a fasthttp request:
+// status_code, response_size and latency_ms
+func ResponseAttrs(ctx *fasthttp.RequestCtx, duration time.Duration) []slog.Attr {
+	return []slog.Attr{
+		slog.Int("status_code", ctx.Response.StatusCode()),
+		slog.Int("response_size", len(ctx.Response.Body())),
+		latencyAttr(duration),
+	}
+}
+
+// HTTPRequestAttrs returns the standard access-log request attributes of a net/http request,
+// using the same keys as RequestAttrs. request_size is -1 when the length is unknown
+func HTTPRequestAttrs(r *http.Request) []slog.Attr {
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIP = r.RemoteAddr
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.String("query", r.URL.RawQuery),
+		slog.String("remote_ip", remoteIP),
+		slog.String("user_agent", r.UserAgent()),
+		slog.Int64("request_size", r.ContentLength),
+	}
+	return appendTraceIDAttr(attrs, GetTraceID(r.Context()))
+}
+
+// appendTraceIDAttr appends traceID under the trace_id key unless it is empty
+func appendTraceIDAttr(attrs []slog.Attr, traceID string) []slog.Attr {
+	if traceID == "" {
+		return attrs
+	}
+	return append(attrs, slog.String("trace_id", traceID))
+}
+
+// HTTPResponseAttrs returns the standard access-log response attributes of a net/http response,
+// using the same keys as ResponseAttrs. status_code and response_size are only included when w
+// reports them through Status() int and BytesWritten() int, as the writer passed to handlers by
+// HTTPMiddleware does
+func HTTPResponseAttrs(w http.ResponseWriter, duration time.Duration) []slog.Attr {
+	var attrs []slog.Attr
+	if sw, ok := w.(interface{ Status() int }); ok {
+		attrs = append(attrs, slog.Int("status_code", sw.Status()))
+	}
+	if bw, ok := w.(interface{ BytesWritten() int }); ok {
+		attrs = append(attrs, slog.Int("response_size", bw.BytesWritten()))
+	}
+	return append(attrs, latencyAttr(duration))
+}
+
+// latencyAttr returns duration as fractional milliseconds under the latency_ms key
+func latencyAttr(duration time.Duration) slog.Attr {
+	return DurationAttrMillis("latency_ms", duration)
+}
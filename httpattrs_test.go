@@ -0,0 +1,89 @@
+package sloglog
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// attrMap returns attrs keyed by attribute key
+func attrMap(attrs []slog.Attr) map[string]slog.Value {
+	m := make(map[string]slog.Value, len(attrs))
+	for _, a := range attrs {
+		m[a.Key] = a.Value
+	}
+	return m
+}
+
+func TestRequestAttrs(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	ctx.Request.SetRequestURI("/orders?limit=10")
+	ctx.Request.Header.SetUserAgent("test-agent")
+	ctx.Request.SetBodyString("payload")
+
+	attrs := attrMap(RequestAttrs(ctx))
+	if _, ok := attrs["trace_id"]; ok {
+		t.Errorf("attrs = %v, want no trace_id without a trace ID", attrs)
+	}
+	if attrs["method"].String() != "POST" || attrs["path"].String() != "/orders" || attrs["query"].String() != "limit=10" ||
+		attrs["user_agent"].String() != "test-agent" || attrs["request_size"].Int64() != 7 {
+		t.Errorf("attrs = %v, want the request fields", attrs)
+	}
+
+	ctx.SetUserValue(TraceIDKey, "trace-1")
+	if got := attrMap(RequestAttrs(ctx))["trace_id"].String(); got != "trace-1" {
+		t.Errorf("trace_id = %q, want trace-1", got)
+	}
+}
+
+func TestResponseAttrs(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.SetStatusCode(fasthttp.StatusCreated)
+	ctx.SetBodyString("created")
+
+	attrs := attrMap(ResponseAttrs(ctx, 1500*time.Microsecond))
+	if attrs["status_code"].Int64() != 201 || attrs["response_size"].Int64() != 7 || attrs["latency_ms"].Float64() != 1.5 {
+		t.Errorf("attrs = %v, want status 201, size 7 and 1.5ms latency", attrs)
+	}
+}
+
+func TestHTTPRequestAttrs(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/users?id=7", strings.NewReader("body"))
+	r.RemoteAddr = "192.0.2.1:5000"
+
+	attrs := attrMap(HTTPRequestAttrs(r))
+	if _, ok := attrs["trace_id"]; ok {
+		t.Errorf("attrs = %v, want no trace_id without a trace ID", attrs)
+	}
+	if attrs["remote_ip"].String() != "192.0.2.1" || attrs["query"].String() != "id=7" || attrs["request_size"].Int64() != 4 {
+		t.Errorf("attrs = %v, want the request fields", attrs)
+	}
+
+	r = r.WithContext(context.WithValue(r.Context(), TraceIDContextKey, "trace-2"))
+	if got := attrMap(HTTPRequestAttrs(r))["trace_id"].String(); got != "trace-2" {
+		t.Errorf("trace_id = %q, want trace-2", got)
+	}
+}
+
+func TestHTTPResponseAttrs(t *testing.T) {
+	rec := &statusRecorder{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK}
+	rec.WriteHeader(http.StatusNotFound)
+	rec.Write([]byte("missing"))
+
+	attrs := attrMap(HTTPResponseAttrs(rec, 2*time.Millisecond))
+	if attrs["status_code"].Int64() != 404 || attrs["response_size"].Int64() != 7 {
+		t.Errorf("attrs = %v, want status 404 and size 7", attrs)
+	}
+
+	attrs = attrMap(HTTPResponseAttrs(httptest.NewRecorder(), time.Millisecond))
+	if _, ok := attrs["status_code"]; ok || len(attrs) != 1 {
+		t.Errorf("attrs of a plain writer = %v, want only latency_ms", attrs)
+	}
+}
@@ -0,0 +1,276 @@
+package sloglog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of HTTPDrainOptions
+const (
+	defaultDrainBatchSize     = 100
+	defaultDrainFlushInterval = time.Second
+	defaultDrainTimeout       = 10 * time.Second
+	drainInitialBackoff       = 100 * time.Millisecond
+)
+
+// HTTPDrainOptions configures a handler created by NewHTTPDrainHandler
+type HTTPDrainOptions struct {
+	// Level is the minimum level of records sent (default: INFO)
+	Level slog.Leveler
+
+	// BatchSize is the number of records sent per request (default: 100)
+	BatchSize int
+
+	// FlushInterval is how often a partial batch is sent (default: 1 second)
+	FlushInterval time.Duration
+
+	// Timeout limits each request (default: 10 seconds)
+	Timeout time.Duration
+
+	// Headers are added to each request, e.g. for authentication
+	Headers map[string]string
+
+	// RetryAttempts is the number of retries of a failed request, with exponential
+	// back-off starting at 100ms (default: 0, no retries)
+	RetryAttempts int
+
+	// QueueSize is the number of records buffered for sending, including those of batches
+	// waiting for a request. Records are dropped when the queue is full (default: 10 * BatchSize)
+	QueueSize int
+}
+
+// HTTPDrainHandler implements slog.Handler by POSTing records as JSON lines to an HTTP endpoint
+type HTTPDrainHandler struct {
+	*CustomHandler
+	drain *httpDrain
+}
+
+// httpDrain batches and sends the lines written by an HTTPDrainHandler and the handlers derived from it
+type httpDrain struct {
+	endpoint string
+	opts     HTTPDrainOptions
+	client   *http.Client
+
+	mu      sync.RWMutex // guards queue against Close
+	queue   chan asyncEntry
+	batches chan drainBatch // batches collected by loop, sent by sendLoop
+	done    chan struct{}
+	dropped atomic.Int64
+}
+
+// drainBatch is a request body of JSON lines, or a flush marker if flushed is set
+type drainBatch struct {
+	body    []byte
+	flushed chan struct{}
+}
+
+// NewHTTPDrainHandler creates a handler that sends records as JSON lines to endpoint in the
+// background, in batches of opts.BatchSize or every opts.FlushInterval. Close sends the
+// remaining records and stops the handler
+func NewHTTPDrainHandler(endpoint string, opts HTTPDrainOptions) (slog.Handler, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid drain endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid drain endpoint %q, expected an http or https URL", endpoint)
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultDrainBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultDrainFlushInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultDrainTimeout
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 10 * opts.BatchSize
+	}
+
+	d := &httpDrain{
+		endpoint: endpoint,
+		opts:     opts,
+		client:   &http.Client{Timeout: opts.Timeout},
+		queue:    make(chan asyncEntry, opts.QueueSize),
+		batches:  make(chan drainBatch, max(1, opts.QueueSize/opts.BatchSize)),
+		done:     make(chan struct{}),
+	}
+	go d.loop()
+	go d.sendLoop()
+
+	h := NewCustomHandler(d, &slog.HandlerOptions{AddSource: true, Level: opts.Level}, true)
+	h.format = FormatJSON
+	return &HTTPDrainHandler{CustomHandler: h, drain: d}, nil
+}
+
+// WithAttrs returns a new HTTPDrainHandler sharing the drain of h whose records include attrs
+func (h *HTTPDrainHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &HTTPDrainHandler{CustomHandler: h.CustomHandler.WithAttrs(attrs).(*CustomHandler), drain: h.drain}
+}
+
+// WithGroup returns a new HTTPDrainHandler sharing the drain of h with the given group opened
+func (h *HTTPDrainHandler) WithGroup(name string) slog.Handler {
+	return &HTTPDrainHandler{CustomHandler: h.CustomHandler.WithGroup(name).(*CustomHandler), drain: h.drain}
+}
+
+// withLeveler returns a new HTTPDrainHandler sharing the drain of h with a different minimum level
+func (h *HTTPDrainHandler) withLeveler(level slog.Leveler) slog.Handler {
+	return &HTTPDrainHandler{CustomHandler: h.CustomHandler.withLeveler(level).(*CustomHandler), drain: h.drain}
+}
+
+// Flush sends the records queued so far and waits until they have been sent
+func (h *HTTPDrainHandler) Flush() {
+	h.drain.mu.RLock()
+	defer h.drain.mu.RUnlock()
+
+	if h.drain.queue == nil {
+		return
+	}
+	flushed := make(chan struct{})
+	h.drain.queue <- asyncEntry{flushed: flushed}
+	<-flushed
+}
+
+// Close sends the queued records and stops the handler and the handlers derived from it.
+// Records handled afterwards are dropped
+func (h *HTTPDrainHandler) Close() error {
+	d := h.drain
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.queue == nil {
+		return nil
+	}
+	close(d.queue)
+	<-d.done
+	d.queue = nil
+	return nil
+}
+
+// Dropped returns the number of records dropped because the queue was full or the handler closed
+func (h *HTTPDrainHandler) Dropped() int64 {
+	return h.drain.dropped.Load()
+}
+
+// Write queues a formatted JSON line, implementing io.Writer for the embedded CustomHandler
+func (d *httpDrain) Write(p []byte) (int, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.queue == nil {
+		d.dropped.Add(1)
+		return len(p), nil
+	}
+	select {
+	case d.queue <- asyncEntry{entry: string(p)}:
+	default:
+		d.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// loop collects queued lines into batches for sendLoop until the queue is closed, so that
+// slow or retried requests do not hold up the queue until the batches are full
+func (d *httpDrain) loop() {
+	defer close(d.batches)
+
+	ticker := time.NewTicker(d.opts.FlushInterval)
+	defer ticker.Stop()
+
+	var batch bytes.Buffer
+	var n int
+	send := func() {
+		if n > 0 {
+			d.batches <- drainBatch{body: batch.Bytes()}
+			batch = bytes.Buffer{}
+			n = 0
+		}
+	}
+
+	for {
+		select {
+		case e, ok := <-d.queue:
+			if !ok {
+				send()
+				return
+			}
+			if e.flushed != nil {
+				send()
+				d.batches <- drainBatch{flushed: e.flushed}
+				continue
+			}
+			batch.WriteString(e.entry)
+			if n++; n >= d.opts.BatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		}
+	}
+}
+
+// sendLoop sends the batches collected by loop until it stops
+func (d *httpDrain) sendLoop() {
+	defer close(d.done)
+
+	for b := range d.batches {
+		if b.flushed != nil {
+			close(b.flushed)
+			continue
+		}
+		d.send(b.body)
+	}
+}
+
+// send POSTs body, retrying failed requests with exponential back-off. A batch that cannot
+// be sent is reported on os.Stderr and discarded
+func (d *httpDrain) send(body []byte) {
+	backoff := drainInitialBackoff
+	var err error
+	for attempt := 0; attempt <= d.opts.RetryAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = d.post(body); err == nil {
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "sloglog: failed to send logs to %s: %v\n", d.endpoint, err)
+}
+
+// post sends body in a single request
+func (d *httpDrain) post(body []byte) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, d.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	for k, v := range d.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	// Drain the body so that the connection can be reused
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package sloglog
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// captureStderr returns what fn writes to os.Stderr
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = prev }()
+
+	fn()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+// drainServer records the request bodies it receives, responding with the given statuses in
+// turn and 200 once they are used up
+type drainServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	statuses []int
+}
+
+func newDrainServer(t *testing.T, statuses ...int) *drainServer {
+	t.Helper()
+	s := &drainServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		status := http.StatusOK
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		if status == http.StatusOK {
+			s.bodies = append(s.bodies, string(body))
+			s.headers = append(s.headers, r.Header.Clone())
+		}
+		s.mu.Unlock()
+
+		w.WriteHeader(status)
+		io.WriteString(w, "accepted\n")
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Bodies returns the bodies of the accepted requests
+func (s *drainServer) Bodies() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+// newTestDrain creates a drain handler for url, closing it when the test finishes
+func newTestDrain(t *testing.T, url string, opts HTTPDrainOptions) *HTTPDrainHandler {
+	t.Helper()
+	h, err := NewHTTPDrainHandler(url, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drain := h.(*HTTPDrainHandler)
+	t.Cleanup(func() { drain.Close() })
+	return drain
+}
+
+func TestHTTPDrainHandler(t *testing.T) {
+	srv := newDrainServer(t)
+	h := newTestDrain(t, srv.URL, HTTPDrainOptions{
+		BatchSize:     3,
+		FlushInterval: time.Hour,
+		Headers:       map[string]string{"Authorization": "Bearer token"},
+	})
+	logger := NewLoggerWithHandler(h)
+
+	for i := range 7 {
+		logger.Info("drained", slog.Int("i", i))
+	}
+	h.Flush()
+
+	bodies := srv.Bodies()
+	if len(bodies) != 3 {
+		t.Fatalf("got %d requests, want batches of 3, 3 and 1", len(bodies))
+	}
+	var i int
+	for _, body := range bodies {
+		for _, line := range strings.Split(strings.TrimSuffix(body, "\n"), "\n") {
+			var record map[string]any
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatalf("invalid JSON line %q: %v", line, err)
+			}
+			if record["msg"] != "drained" || record["i"] != float64(i) {
+				t.Errorf("record %d = %v", i, record)
+			}
+			i++
+		}
+	}
+	if i != 7 {
+		t.Errorf("got %d records, want 7", i)
+	}
+
+	srv.mu.Lock()
+	header := srv.headers[0]
+	srv.mu.Unlock()
+	if header.Get("Authorization") != "Bearer token" || header.Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("request headers = %v", header)
+	}
+}
+
+func TestHTTPDrainRetry(t *testing.T) {
+	srv := newDrainServer(t, http.StatusInternalServerError, http.StatusServiceUnavailable)
+	h := newTestDrain(t, srv.URL, HTTPDrainOptions{FlushInterval: time.Hour, RetryAttempts: 2})
+
+	NewLoggerWithHandler(h).Info("retried")
+	h.Flush()
+
+	if bodies := srv.Bodies(); len(bodies) != 1 || !strings.Contains(bodies[0], `"msg":"retried"`) {
+		t.Errorf("accepted bodies = %q, want the retried record", bodies)
+	}
+}
+
+func TestHTTPDrainFailureReported(t *testing.T) {
+	srv := newDrainServer(t, http.StatusBadRequest)
+	h := newTestDrain(t, srv.URL, HTTPDrainOptions{FlushInterval: time.Hour})
+
+	out := captureStderr(t, func() {
+		NewLoggerWithHandler(h).Info("rejected")
+		h.Flush()
+	})
+
+	if !strings.Contains(out, "sloglog: failed to send logs") || !strings.Contains(out, "400") {
+		t.Errorf("stderr = %q, want the failed request", out)
+	}
+}
+
+func TestHTTPDrainRegistryChild(t *testing.T) {
+	srv := newDrainServer(t)
+	h := newTestDrain(t, srv.URL, HTTPDrainOptions{FlushInterval: time.Hour})
+	r := NewRegistry(NewLoggerWithHandler(h))
+	r.Configure("db", slog.LevelDebug)
+
+	db := r.Get("db")
+	child, ok := db.logger.Handler().(*HTTPDrainHandler)
+	if !ok {
+		t.Fatalf("registry child handler is %T, want *HTTPDrainHandler", db.logger.Handler())
+	}
+	db.Debug("query")
+	child.Flush()
+
+	if bodies := srv.Bodies(); len(bodies) != 1 || !strings.Contains(bodies[0], `"logger":"db"`) {
+		t.Errorf("accepted bodies = %q, want the DEBUG record of db", bodies)
+	}
+
+	child.Close()
+	db.Info("after close")
+	if h.Dropped() != 1 {
+		t.Errorf("Dropped = %d after closing through the child, want 1", h.Dropped())
+	}
+}
+
+func TestNewHTTPDrainHandlerInvalidEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"", "ftp://logs.example.com", "://bad"} {
+		if _, err := NewHTTPDrainHandler(endpoint, HTTPDrainOptions{}); err == nil {
+			t.Errorf("NewHTTPDrainHandler(%q) succeeded, want an error", endpoint)
+		}
+	}
+}
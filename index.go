@@ -0,0 +1,139 @@
+package sloglog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// defaultIndexFile is the name of the archive index kept in the log directory
+const defaultIndexFile = "index.json"
+
+// IndexEntry records the time from which a log file receives records
+type IndexEntry struct {
+	Start time.Time `json:"start"`
+	File  string    `json:"file"` // name relative to the log directory
+}
+
+// start returns the beginning of the rotation period containing t
+func (s RotationSchedule) start(t time.Time) time.Time {
+	year, month, day := t.Date()
+	switch s {
+	case RotateHourly:
+		return time.Date(year, month, day, t.Hour(), 0, 0, 0, t.Location())
+	case RotateWeekly:
+		// ISO weeks start on Monday
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, t.Location())
+	case RotateMonthly:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// loadIndex restores the archive index from disk, leaving it empty if the index file is
+// missing or unreadable
+func (fl *FileLogger) loadIndex() {
+	data, err := os.ReadFile(filepath.Join(fl.opts.Dir, fl.opts.IndexFile))
+	if err != nil {
+		return
+	}
+
+	var index []IndexEntry
+	if err := json.Unmarshal(data, &index); err != nil {
+		return
+	}
+	sort.Slice(index, func(i, j int) bool {
+		return index[i].Start.Before(index[j].Start)
+	})
+
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	fl.index = index
+}
+
+// updateIndex records that file receives records from start and persists the index.
+// Entries of files that no longer exist are dropped. fl.mu must be held
+func (fl *FileLogger) updateIndex(start time.Time, file string) {
+	if n := len(fl.index); n > 0 && fl.index[n-1].File == file {
+		return
+	}
+
+	index := fl.index[:0]
+	for _, e := range fl.index {
+		if _, err := os.Stat(fl.indexedPath(e.File)); err == nil {
+			index = append(index, e)
+		}
+	}
+	fl.index = append(index, IndexEntry{Start: start, File: file})
+
+	if err := fl.saveIndex(); err != nil {
+		fmt.Fprintf(os.Stderr, "sloglog: %v\n", err)
+	}
+}
+
+// saveIndex writes the index to a temporary file and renames it over the index file, so that
+// readers never observe a partially written index. fl.mu must be held
+func (fl *FileLogger) saveIndex() error {
+	data, err := json.MarshalIndent(fl.index, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(fl.opts.Dir, fl.opts.IndexFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write log index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace log index: %w", err)
+	}
+	return nil
+}
+
+// Index returns a copy of the archive index, oldest file first
+func (fl *FileLogger) Index() []IndexEntry {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	return append([]IndexEntry(nil), fl.index...)
+}
+
+// indexedPath returns the path of the indexed file, or of its compressed copy once it has
+// been replaced by one
+func (fl *FileLogger) indexedPath(file string) string {
+	path := filepath.Join(fl.opts.Dir, file)
+	if _, err := os.Stat(path); err != nil {
+		if _, err := os.Stat(path + ".gz"); err == nil {
+			return path + ".gz"
+		}
+	}
+	return path
+}
+
+// LookupFile returns the path of the log file most likely containing the records written at t,
+// i.e. the newest file whose period started at or before t. Compressed files are returned
+// with their .gz extension
+func (fl *FileLogger) LookupFile(t time.Time) (string, error) {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	i := sort.Search(len(fl.index), func(i int) bool {
+		return fl.index[i].Start.After(t)
+	})
+	if i == 0 {
+		return "", fmt.Errorf("no log file in %s contains records for %s", fl.opts.Dir, t.Format(time.RFC3339))
+	}
+	return fl.indexedPath(fl.index[i-1].File), nil
+}
+
+// LookupLogFile returns the path of the file of the package-level file logger most likely
+// containing the records written at t
+func LookupLogFile(t time.Time) (string, error) {
+	return fileLogger.Load().LookupFile(t)
+}
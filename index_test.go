@@ -0,0 +1,110 @@
+package sloglog
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndexRoundTrip(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 14, 10, 30, 0, 0, time.UTC)}
+	opts := FileLoggerOptions{Dir: t.TempDir(), Schedule: RotateHourly}
+	fl := newTestFileLogger(t, opts, clock)
+
+	fl.writeToFile("10:30")
+	clock.now = clock.now.Add(time.Hour)
+	fl.writeToFile("11:30")
+	fl.Close()
+
+	want := []IndexEntry{
+		{Start: time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC), File: "2026-03-14_10.log"},
+		{Start: time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC), File: "2026-03-14_11.log"},
+	}
+	if got := fl.Index(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("index = %v, want %v", got, want)
+	}
+
+	restarted := newFileLogger(opts)
+	if got := restarted.Index(); len(got) != 0 {
+		t.Errorf("index loaded before enable: %v", got)
+	}
+	restarted.enable()
+	defer restarted.Close()
+	got := restarted.Index()
+	for i := range got {
+		got[i].Start = got[i].Start.UTC()
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reloaded index = %v, want %v", got, want)
+	}
+}
+
+func TestLookupFileAcrossRotation(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 14, 10, 30, 0, 0, time.UTC)}
+	fl := newTestFileLogger(t, FileLoggerOptions{Schedule: RotateHourly}, clock)
+
+	fl.writeToFile("10:30")
+	clock.now = clock.now.Add(time.Hour)
+	fl.writeToFile("11:30")
+
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC), "2026-03-14_10.log"},
+		{time.Date(2026, 3, 14, 10, 59, 59, 0, time.UTC), "2026-03-14_10.log"},
+		{time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC), "2026-03-14_11.log"},
+		{time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), "2026-03-14_11.log"},
+	}
+	for _, tt := range tests {
+		path, err := fl.LookupFile(tt.t)
+		if err != nil || path != filepath.Join(fl.opts.Dir, tt.want) {
+			t.Errorf("LookupFile(%v) = %q, %v, want %s", tt.t, path, err, tt.want)
+		}
+	}
+
+	if path, err := fl.LookupFile(time.Date(2026, 3, 14, 9, 59, 0, 0, time.UTC)); err == nil {
+		t.Errorf("LookupFile before the first file = %q, want an error", path)
+	}
+}
+
+func TestIndexPerLogger(t *testing.T) {
+	dir := t.TempDir()
+	main := newFileLogger(FileLoggerOptions{Dir: dir})
+	main.enable()
+	defer main.Close()
+	logger, _ := newBufferLogger()
+	logger = logger.WithAuxFile("audit", dir, FileLoggerOptions{FilenameTemplate: "audit_2006-01-02"})
+	defer logger.CloseAuxFiles()
+
+	main.writeToFile("main")
+	logger.auxFiles[0].fl.writeToFile("audit")
+
+	for index, file := range map[string]string{"index.json": "main", "audit_index.json": "audit"} {
+		data, err := os.ReadFile(filepath.Join(dir, index))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := strings.Count(string(data), `"file"`); n != 1 {
+			t.Errorf("%s has %d entries, want only the %s file:\n%s", index, n, file, data)
+		}
+	}
+}
+
+func TestSaveIndexErrorReported(t *testing.T) {
+	dir := t.TempDir()
+	// A directory in place of the index file makes the rename fail
+	if err := os.MkdirAll(filepath.Join(dir, defaultIndexFile, "blocker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	fl := newTestFileLogger(t, FileLoggerOptions{Dir: dir}, &testClock{now: time.Now()})
+	out := captureStderr(t, func() { fl.writeToFile("entry") })
+
+	if !strings.Contains(out, "sloglog: failed to replace log index") {
+		t.Errorf("stderr = %q, want the index error", out)
+	}
+}
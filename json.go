@@ -0,0 +1,165 @@
+package sloglog
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+)
+
+// handleJSON writes r as a single JSON object, nesting attributes under their groups
+func (h *CustomHandler) handleJSON(r slog.Record) error {
+	buf := make([]byte, 0, 256)
+	buf = append(buf, '{')
+	buf = appendJSONKey(buf, cmp.Or(h.timeKey, slog.TimeKey))
+	if h.timeFormat == TimestampUnixMilli {
+		buf = strconv.AppendInt(buf, r.Time.UnixMilli(), 10)
+	} else {
+		buf = appendJSONString(buf, formatTimestamp(r.Time, h.timeFormat, time.RFC3339Nano))
+	}
+	buf = appendJSONKey(buf, cmp.Or(h.levelKey, slog.LevelKey))
+	buf = appendJSONString(buf, formatLevel(r.Level))
+	buf = appendJSONKey(buf, cmp.Or(h.messageKey, slog.MessageKey))
+	buf = appendJSONString(buf, r.Message)
+
+	// Source stays at the top level regardless of open groups
+	recordAttrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "source" {
+			if h.addSource && h.opts.AddSource {
+				buf = appendJSONAttr(buf, a)
+			}
+			return true
+		}
+		recordAttrs = append(recordAttrs, a)
+		return true
+	})
+
+	buf = h.appendJSONGroup(buf, 0, recordAttrs)
+	buf = append(buf, '}', '\n')
+
+	_, err := h.writer.Write(buf)
+	return err
+}
+
+// appendJSONGroup appends the attributes added at depth followed by the groups nested below it.
+// The record attributes belong to the innermost group
+func (h *CustomHandler) appendJSONGroup(buf []byte, depth int, recordAttrs []slog.Attr) []byte {
+	if depth < len(h.attrs) {
+		for _, a := range h.attrs[depth] {
+			buf = appendJSONAttr(buf, a)
+		}
+	}
+
+	if depth == len(h.groupStack) {
+		for _, a := range recordAttrs {
+			buf = appendJSONAttr(buf, a)
+		}
+		return buf
+	}
+
+	// Omit groups that would be empty
+	if !h.hasJSONAttrs(depth+1, recordAttrs) {
+		return buf
+	}
+
+	buf = appendJSONKey(buf, h.groupStack[depth])
+	buf = append(buf, '{')
+	buf = h.appendJSONGroup(buf, depth+1, recordAttrs)
+	return append(buf, '}')
+}
+
+// hasJSONAttrs reports whether any attributes live at or below depth
+func (h *CustomHandler) hasJSONAttrs(depth int, recordAttrs []slog.Attr) bool {
+	if len(recordAttrs) > 0 {
+		return true
+	}
+	for i := depth; i < len(h.attrs); i++ {
+		if len(h.attrs[i]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// appendJSONAttr appends a as a JSON member, rendering groups as nested objects
+func appendJSONAttr(buf []byte, a slog.Attr) []byte {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return buf
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return buf
+		}
+		// Groups with an empty key are inlined
+		if a.Key == "" {
+			for _, ga := range attrs {
+				buf = appendJSONAttr(buf, ga)
+			}
+			return buf
+		}
+		buf = appendJSONKey(buf, a.Key)
+		buf = append(buf, '{')
+		for _, ga := range attrs {
+			buf = appendJSONAttr(buf, ga)
+		}
+		return append(buf, '}')
+	}
+
+	buf = appendJSONKey(buf, a.Key)
+	return appendJSONValue(buf, a.Value)
+}
+
+// appendJSONKey appends a member key, preceded by a comma unless it opens an object
+func appendJSONKey(buf []byte, key string) []byte {
+	if len(buf) > 0 && buf[len(buf)-1] != '{' {
+		buf = append(buf, ',')
+	}
+	buf = appendJSONString(buf, key)
+	return append(buf, ':')
+}
+
+// appendJSONValue appends v encoded as JSON
+func appendJSONValue(buf []byte, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindString:
+		return appendJSONString(buf, v.String())
+	case slog.KindInt64:
+		return strconv.AppendInt(buf, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(buf, v.Uint64(), 10)
+	case slog.KindFloat64:
+		f := v.Float64()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return appendJSONString(buf, strconv.FormatFloat(f, 'g', -1, 64))
+		}
+		return strconv.AppendFloat(buf, f, 'g', -1, 64)
+	case slog.KindBool:
+		return strconv.AppendBool(buf, v.Bool())
+	case slog.KindDuration:
+		return strconv.AppendInt(buf, int64(v.Duration()), 10)
+	case slog.KindTime:
+		return appendJSONString(buf, v.Time().Format(time.RFC3339Nano))
+	default:
+		if err, ok := v.Any().(error); ok {
+			return appendJSONString(buf, err.Error())
+		}
+		b, err := json.Marshal(v.Any())
+		if err != nil {
+			return appendJSONString(buf, fmt.Sprint(v.Any()))
+		}
+		return append(buf, b...)
+	}
+}
+
+// appendJSONString appends s as a quoted JSON string
+func appendJSONString(buf []byte, s string) []byte {
+	b, _ := json.Marshal(s)
+	return append(buf, b...)
+}
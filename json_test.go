@@ -0,0 +1,74 @@
+package sloglog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestCustomHandlerGroupsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	slog.New(newGroupedHandler(&buf, FormatJSON)).Info("connected", slog.Int("size", 10))
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if record["app"] != "billing" {
+		t.Errorf("app = %v, want billing at the top level", record["app"])
+	}
+	want := map[string]any{
+		"host": "primary",
+		"pool": map[string]any{"size": float64(10)},
+	}
+	if !reflect.DeepEqual(record["db"], want) {
+		t.Errorf("db = %v, want %v", record["db"], want)
+	}
+}
+
+func TestCustomHandlerEmptyGroupJSON(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCustomHandler(&buf, nil, false)
+	h.format = FormatJSON
+	slog.New(h.WithGroup("db")).Info("no attributes")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if _, ok := record["db"]; ok {
+		t.Errorf("record %v has an empty db group", record)
+	}
+}
+
+func TestJSONKeys(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON), WithMessageKey("message"), WithLevelKey("severity"), WithTimeKey("@timestamp"))
+	logger.Warn("disk almost full")
+
+	record := decodeJSONLines(t, buf)[0]
+	if record["message"] != "disk almost full" || record["severity"] != "WARN" {
+		t.Errorf("record = %v, want message and severity keys", record)
+	}
+	if _, ok := record["@timestamp"].(string); !ok {
+		t.Errorf("record = %v, want a @timestamp key", record)
+	}
+	for _, key := range []string{slog.MessageKey, slog.LevelKey, slog.TimeKey} {
+		if _, ok := record[key]; ok {
+			t.Errorf("record %v still has the default key %s", record, key)
+		}
+	}
+}
+
+func TestJSONDefaultKeys(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+	logger.Info("defaults")
+
+	record := decodeJSONLines(t, buf)[0]
+	for _, key := range []string{slog.MessageKey, slog.LevelKey, slog.TimeKey} {
+		if _, ok := record[key]; !ok {
+			t.Errorf("record %v has no %s key", record, key)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package sloglog
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultLatencyBuckets are the upper bounds of the histogram buckets used when none are given
+var defaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// LatencyTracker accumulates durations per label in histograms and logs summaries of them.
+// It is safe for concurrent use
+type LatencyTracker struct {
+	mu         sync.Mutex
+	buckets    []time.Duration // sorted upper bounds
+	histograms map[string]*histogram
+}
+
+// histogram counts the durations recorded for a label
+type histogram struct {
+	counts   []int64 // counts[i] holds durations <= buckets[i], the last one those above all bounds
+	count    int64
+	min, max time.Duration
+}
+
+// NewLatencyTracker creates a tracker using buckets as the upper bounds of the histogram buckets.
+// Percentiles are interpolated within buckets, so their precision depends on the bounds
+// (default: 1ms to 10s in roughly 2.5x steps)
+func NewLatencyTracker(buckets ...time.Duration) *LatencyTracker {
+	if len(buckets) == 0 {
+		buckets = defaultLatencyBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return &LatencyTracker{
+		buckets:    slices.Compact(buckets),
+		histograms: make(map[string]*histogram),
+	}
+}
+
+// Record adds d to the histogram of label
+func (t *LatencyTracker) Record(label string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := t.histograms[label]
+	if !ok {
+		h = &histogram{counts: make([]int64, len(t.buckets)+1), min: d, max: d}
+		t.histograms[label] = h
+	}
+
+	i, _ := slices.BinarySearch(t.buckets, d)
+	h.counts[i]++
+	h.count++
+	h.min = min(h.min, d)
+	h.max = max(h.max, d)
+}
+
+// Reset discards all recorded durations
+func (t *LatencyTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	clear(t.histograms)
+}
+
+// FlushSummary logs one INFO record per label with the count, min, max and the 50th, 95th
+// and 99th percentiles of its durations in milliseconds. A nil logger uses the default logger
+func (t *LatencyTracker) FlushSummary(ctx context.Context, logger *Logger) {
+	t.logSummaries(ctx, logger, callerSource(1), t.summaries(false))
+}
+
+// StartPeriodicFlush logs the summaries every interval and resets the histograms, until ctx is done.
+// The records are attributed to the call of StartPeriodicFlush
+func (t *LatencyTracker) StartPeriodicFlush(ctx context.Context, logger *Logger, interval time.Duration) {
+	source := callerSource(1)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				t.logSummaries(ctx, logger, source, t.summaries(true))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// summaries returns the summary attributes of every label, sorted by label, optionally
+// resetting the histograms within the same critical section
+func (t *LatencyTracker) summaries(reset bool) [][]slog.Attr {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	labels := make([]string, 0, len(t.histograms))
+	for label := range t.histograms {
+		labels = append(labels, label)
+	}
+	slices.Sort(labels)
+
+	summaries := make([][]slog.Attr, 0, len(labels))
+	for _, label := range labels {
+		h := t.histograms[label]
+		summaries = append(summaries, []slog.Attr{
+			slog.String("label", label),
+			slog.Int64("count", h.count),
+			DurationAttrMillis("min_ms", h.min),
+			DurationAttrMillis("max_ms", h.max),
+			DurationAttrMillis("p50_ms", t.percentile(h, 0.50)),
+			DurationAttrMillis("p95_ms", t.percentile(h, 0.95)),
+			DurationAttrMillis("p99_ms", t.percentile(h, 0.99)),
+		})
+	}
+
+	if reset {
+		clear(t.histograms)
+	}
+	return summaries
+}
+
+// logSummaries logs each summary as an INFO record attributed to source
+func (t *LatencyTracker) logSummaries(ctx context.Context, logger *Logger, source string, summaries [][]slog.Attr) {
+	if logger == nil {
+		logger = defaultLogger.Load()
+	}
+	for _, attrs := range summaries {
+		args := make([]any, len(attrs))
+		for i, attr := range attrs {
+			args[i] = attr
+		}
+		logger.logWithSource(ctx, source, slog.LevelInfo, "latency summary", args...)
+	}
+}
+
+// percentile estimates the duration below which the fraction q of the durations in h fall,
+// interpolating linearly within the bucket containing it. t.mu must be held
+func (t *LatencyTracker) percentile(h *histogram, q float64) time.Duration {
+	rank := q * float64(h.count)
+
+	var seen int64
+	for i, n := range h.counts {
+		if n == 0 || float64(seen+n) < rank {
+			seen += n
+			continue
+		}
+
+		// Bounds of the bucket, narrowed to the observed range
+		lower, upper := h.min, h.max
+		if i > 0 {
+			lower = max(lower, t.buckets[i-1])
+		}
+		if i < len(t.buckets) {
+			upper = min(upper, t.buckets[i])
+		}
+
+		frac := (rank - float64(seen)) / float64(n)
+		return lower + time.Duration(frac*float64(upper-lower))
+	}
+	return h.max
+}
@@ -0,0 +1,143 @@
+package sloglog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLatencyTrackerPercentiles(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+	tracker := NewLatencyTracker()
+
+	// 1ms to 1000ms, uniformly distributed
+	for i := 1; i <= 1000; i++ {
+		tracker.Record("GET /users", time.Duration(i)*time.Millisecond)
+	}
+	tracker.FlushSummary(context.Background(), logger)
+
+	records := decodeJSONLines(t, buf)
+	if len(records) != 1 {
+		t.Fatalf("got %d summaries, want 1", len(records))
+	}
+	summary := records[0]
+	if summary["label"] != "GET /users" || summary["count"] != float64(1000) ||
+		summary["min_ms"] != float64(1) || summary["max_ms"] != float64(1000) {
+		t.Errorf("summary = %v", summary)
+	}
+	for key, want := range map[string]float64{"p50_ms": 500, "p95_ms": 950, "p99_ms": 990} {
+		got, _ := summary[key].(float64)
+		if math.Abs(got-want) > want*0.05 {
+			t.Errorf("%s = %v, want within 5%% of %v", key, got, want)
+		}
+	}
+}
+
+func TestLatencyTrackerCustomBuckets(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+	tracker := NewLatencyTracker(20*time.Millisecond, 10*time.Millisecond, 10*time.Millisecond)
+
+	for range 10 {
+		tracker.Record("a", 5*time.Millisecond)
+		tracker.Record("b", 15*time.Millisecond)
+	}
+	tracker.FlushSummary(context.Background(), logger)
+
+	records := decodeJSONLines(t, buf)
+	if len(records) != 2 || records[0]["label"] != "a" || records[1]["label"] != "b" {
+		t.Fatalf("summaries = %v, want one per label sorted by label", records)
+	}
+	if records[0]["p50_ms"] != float64(5) || records[1]["p50_ms"] != float64(15) {
+		t.Errorf("p50 = %v and %v, want 5 and 15", records[0]["p50_ms"], records[1]["p50_ms"])
+	}
+}
+
+func TestLatencyTrackerConcurrent(t *testing.T) {
+	tracker := NewLatencyTracker()
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				tracker.Record(fmt.Sprint("label", g%2), time.Duration(i)*time.Millisecond)
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 10 {
+			tracker.FlushSummary(context.Background(), NewLogger(WithWriter(io.Discard)))
+		}
+	}()
+	wg.Wait()
+
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+	tracker.FlushSummary(context.Background(), logger)
+	for _, summary := range decodeJSONLines(t, buf) {
+		if summary["count"] != float64(400) {
+			t.Errorf("%v count = %v, want 400", summary["label"], summary["count"])
+		}
+	}
+}
+
+func TestLatencyTrackerFlushSource(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+	tracker := NewLatencyTracker()
+	tracker.Record("a", time.Millisecond)
+
+	_, file, line, _ := runtime.Caller(0)
+	tracker.FlushSummary(context.Background(), logger)
+
+	if got, want := sourceOf(t, buf), fmt.Sprintf("[%s:%d]", file, line+1); got != want {
+		t.Errorf("source = %s, want the call of FlushSummary %s", got, want)
+	}
+}
+
+func TestLatencyTrackerPeriodicFlush(t *testing.T) {
+	// The after hook reports each written summary, so that the test waits for the write
+	// to complete before it moves on
+	written := make(chan slog.Record, 1)
+	logger, out := newBufferLogger(WithFormat(FormatJSON))
+	logger = logger.WithHooks(nil, func(r slog.Record) slog.Record {
+		written <- r
+		return r
+	})
+	tracker := NewLatencyTracker()
+	tracker.Record("a", time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, file, line, _ := runtime.Caller(0)
+	tracker.StartPeriodicFlush(ctx, logger, 10*time.Millisecond)
+
+	select {
+	case r := <-written:
+		if r.Message != "latency summary" {
+			t.Errorf("periodic flush logged %q, want the latency summary", r.Message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no summary logged by the periodic flush")
+	}
+	cancel()
+
+	want := fmt.Sprintf("[%s:%d]", file, line+1)
+	if !strings.Contains(out.String(), `"source":"`+want+`"`) {
+		t.Errorf("summary %q is not attributed to the call of StartPeriodicFlush %s", out, want)
+	}
+
+	// The histograms were reset by the flush
+	direct, buf := newBufferLogger(WithFormat(FormatJSON))
+	tracker.FlushSummary(context.Background(), direct)
+	if buf.Len() != 0 {
+		t.Errorf("summary after the periodic flush = %q, want none", buf)
+	}
+}
@@ -0,0 +1,20 @@
+package sloglog
+
+import "log/slog"
+
+// LazyValue computes the value of an attribute created by LazyAttr when it is needed
+type LazyValue func() any
+
+// LogValue implements slog.LogValuer, so handlers outside Logger also evaluate the value lazily
+func (v LazyValue) LogValue() slog.Value {
+	return slog.AnyValue(v())
+}
+
+// LazyAttr returns an attribute whose value is computed by fn only if the level of the record is
+// enabled, e.g. for an expensive DEBUG-only dump passed alongside cheap attributes.
+// Unlike DebugFunc, the other attributes of the call are still evaluated eagerly.
+// Passed to Debug and the other ...any methods, the attribute is boxed and allocates even at a
+// disabled level; LogAttrs is the path that does not allocate
+func LazyAttr(key string, fn func() any) slog.Attr {
+	return slog.Any(key, LazyValue(fn))
+}
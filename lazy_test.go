@@ -0,0 +1,95 @@
+package sloglog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLazyAttr(t *testing.T) {
+	logger, buf := newBufferLogger(WithLevel(slog.LevelInfo), WithFormat(FormatJSON))
+	calls := 0
+	dump := func() any {
+		calls++
+		return "expensive"
+	}
+
+	logger.Debug("disabled", slog.Int("cheap", 1), LazyAttr("dump", dump))
+	if calls != 0 || buf.Len() != 0 {
+		t.Fatalf("disabled level evaluated the lazy value %d times and wrote %q", calls, buf)
+	}
+
+	logger.Info("enabled", slog.Int("cheap", 1), LazyAttr("dump", dump))
+	if calls != 1 {
+		t.Errorf("enabled level evaluated the lazy value %d times, want 1", calls)
+	}
+	if record := decodeJSONLines(t, buf)[0]; record["dump"] != "expensive" || record["cheap"] != float64(1) {
+		t.Errorf("record = %v, want the lazy and the eager attribute", record)
+	}
+}
+
+func TestLazyAttrStandardHandler(t *testing.T) {
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("msg", LazyAttr("dump", func() any { return "computed" }))
+
+	if !strings.Contains(buf.String(), "dump=computed") {
+		t.Errorf("slog.TextHandler output %q does not contain the resolved lazy value", buf.String())
+	}
+}
+
+// dumpState stands in for an expensive conversion to a loggable value
+func dumpState() any {
+	return strings.Repeat("x", 256)
+}
+
+func TestLazyAttrDisabledAllocs(t *testing.T) {
+	logger, _ := newBufferLogger(WithLevel(slog.LevelInfo))
+	ctx := context.Background()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.LogAttrs(ctx, slog.LevelDebug, "disabled", slog.Int("size", 10), LazyAttr("dump", dumpState))
+	})
+	if allocs != 0 {
+		t.Errorf("LogAttrs with a lazy attribute allocates %v times per call at a disabled level, want 0", allocs)
+	}
+}
+
+func TestLogAttrs(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+
+	logger.LogAttrs(context.Background(), slog.LevelWarn, "attrs", slog.Int("size", 10), LazyAttr("dump", func() any { return "computed" }))
+
+	record := decodeJSONLines(t, buf)[0]
+	if record["level"] != "WARN" || record["size"] != float64(10) || record["dump"] != "computed" {
+		t.Errorf("record = %v, want the WARN record with both attributes", record)
+	}
+	if source, _ := record["source"].(string); !strings.Contains(source, "lazy_test.go") {
+		t.Errorf("source = %v, want the calling line", record["source"])
+	}
+}
+
+func BenchmarkAttrDisabled(b *testing.B) {
+	logger, _ := newBufferLogger(WithLevel(slog.LevelInfo))
+
+	b.Run("eager", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			logger.Debug("disabled", slog.Any("dump", dumpState()))
+		}
+	})
+	b.Run("lazy", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			logger.Debug("disabled", LazyAttr("dump", dumpState))
+		}
+	})
+	b.Run("lazy LogAttrs", func(b *testing.B) {
+		ctx := context.Background()
+		b.ReportAllocs()
+		for b.Loop() {
+			logger.LogAttrs(ctx, slog.LevelDebug, "disabled", LazyAttr("dump", dumpState))
+		}
+	})
+}
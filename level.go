@@ -0,0 +1,118 @@
+package sloglog
+
+import (
+	"context"
+	"log/slog"
+	"math"
+)
+
+// levelOverrider is implemented by handlers whose minimum level can be replaced
+type levelOverrider interface {
+	leveler() slog.Leveler
+	withLeveler(level slog.Leveler) slog.Handler
+}
+
+// leveler returns the minimum level of the handler
+func (h *CustomHandler) leveler() slog.Leveler {
+	if h.opts.Level == nil {
+		return slog.LevelInfo
+	}
+	return h.opts.Level
+}
+
+// withLeveler returns a copy of the handler with a different minimum level
+func (h *CustomHandler) withLeveler(level slog.Leveler) slog.Handler {
+	h2 := *h
+	h2.opts.Level = level
+	return &h2
+}
+
+// leveler returns the minimum level of the low handler
+func (h *SplitHandler) leveler() slog.Leveler {
+	if lo, ok := h.low.(levelOverrider); ok {
+		return lo.leveler()
+	}
+	return minLevel
+}
+
+// withLeveler returns a copy of the handler with a different minimum level for both handlers
+func (h *SplitHandler) withLeveler(level slog.Leveler) slog.Handler {
+	return NewSplitHandler(h.threshold, withLeveler(h.low, level), withLeveler(h.high, level))
+}
+
+// leveler returns the minimum level of the first handler
+func (h *MultiHandler) leveler() slog.Leveler {
+	if len(h.handlers) > 0 {
+		if lo, ok := h.handlers[0].(levelOverrider); ok {
+			return lo.leveler()
+		}
+	}
+	return minLevel
+}
+
+// withLeveler returns a copy of the handler with a different minimum level for all handlers
+func (h *MultiHandler) withLeveler(level slog.Leveler) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = withLeveler(handler, level)
+	}
+	return NewMultiHandler(handlers...)
+}
+
+// minLevel lets every record through, leaving filtering to the wrapped handler
+const minLevel = slog.Level(math.MinInt)
+
+// leveler returns the minimum level of the logger's handler, or minLevel if it cannot be determined
+func (l *Logger) leveler() slog.Leveler {
+	if h, ok := l.logger.Handler().(levelOverrider); ok {
+		return h.leveler()
+	}
+	return minLevel
+}
+
+// withLeveler returns a child logger whose handler uses a different minimum level
+func (l *Logger) withLeveler(level slog.Leveler) *Logger {
+	child := l.clone()
+	child.logger = slog.New(withLeveler(l.logger.Handler(), level))
+	if l.ungrouped != nil {
+		child.ungrouped = withLeveler(l.ungrouped, level)
+	}
+	return child
+}
+
+// withLeveler replaces the minimum level of h, wrapping it in a levelHandler when h is opaque
+func withLeveler(h slog.Handler, level slog.Leveler) slog.Handler {
+	if lo, ok := h.(levelOverrider); ok {
+		return lo.withLeveler(level)
+	}
+	return &levelHandler{level: level, handler: h}
+}
+
+// levelHandler filters records below level before delegating to a handler
+type levelHandler struct {
+	level   slog.Leveler
+	handler slog.Handler
+}
+
+// Enabled reports whether level passes both the filter and the wrapped handler
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.handler.Enabled(ctx, level)
+}
+
+// Handle forwards the Record if its level passes the filter
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.level.Level() {
+		return nil
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a new levelHandler wrapping h's handler with attrs
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{level: h.level, handler: h.handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a new levelHandler wrapping h's handler with the given group
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{level: h.level, handler: h.handler.WithGroup(name)}
+}
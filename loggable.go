@@ -0,0 +1,48 @@
+package sloglog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Loggable is the logging interface implemented by *Logger. Accept it instead of *Logger
+// to let callers inject a test double or NopLogger.
+//
+// Go has no covariant return types, so the child constructor is WithAttrs rather than
+// With, which returns the concrete *Logger
+type Loggable interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+	DebugCtx(ctx context.Context, msg string, args ...any)
+	InfoCtx(ctx context.Context, msg string, args ...any)
+	WarnCtx(ctx context.Context, msg string, args ...any)
+	ErrorCtx(ctx context.Context, msg string, args ...any)
+	WithAttrs(attrs ...slog.Attr) Loggable
+}
+
+var _ Loggable = (*Logger)(nil)
+
+// WithAttrs returns a child logger with pre-set attributes as a Loggable
+func (l *Logger) WithAttrs(attrs ...slog.Attr) Loggable {
+	return l.With(attrs...)
+}
+
+// NopLogger returns a Loggable that discards all records
+func NopLogger() Loggable {
+	return nopLogger{}
+}
+
+// nopLogger implements Loggable by doing nothing
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...any)                         {}
+func (nopLogger) Info(msg string, args ...any)                          {}
+func (nopLogger) Warn(msg string, args ...any)                          {}
+func (nopLogger) Error(msg string, args ...any)                         {}
+func (nopLogger) DebugCtx(ctx context.Context, msg string, args ...any) {}
+func (nopLogger) InfoCtx(ctx context.Context, msg string, args ...any)  {}
+func (nopLogger) WarnCtx(ctx context.Context, msg string, args ...any)  {}
+func (nopLogger) ErrorCtx(ctx context.Context, msg string, args ...any) {}
+func (n nopLogger) WithAttrs(attrs ...slog.Attr) Loggable               { return n }
@@ -0,0 +1,67 @@
+package sloglog
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+// recordingLoggable is a test double recording the messages it receives
+type recordingLoggable struct {
+	nopLogger
+	messages *[]string
+}
+
+func (r recordingLoggable) Info(msg string, args ...any) {
+	*r.messages = append(*r.messages, msg)
+}
+
+func (r recordingLoggable) WithAttrs(attrs ...slog.Attr) Loggable {
+	return r
+}
+
+// chargeService is a dependency accepting a Loggable
+type chargeService struct {
+	log Loggable
+}
+
+func (s chargeService) charge() {
+	s.log.WithAttrs(slog.String("svc", "payments")).Info("payment processed")
+}
+
+func TestLoggableTestDouble(t *testing.T) {
+	var messages []string
+	chargeService{log: recordingLoggable{messages: &messages}}.charge()
+
+	if len(messages) != 1 || messages[0] != "payment processed" {
+		t.Errorf("messages = %q, want the processed payment", messages)
+	}
+}
+
+func TestLoggableLogger(t *testing.T) {
+	logger, h := NewTestLogger(t)
+	chargeService{log: logger}.charge()
+
+	records := h.Records()
+	if len(records) != 1 || records[0].Message != "payment processed" {
+		t.Fatalf("records = %v, want the processed payment", records)
+	}
+	var svc string
+	records[0].Attrs(func(a slog.Attr) bool {
+		if a.Key == "svc" {
+			svc = a.Value.String()
+		}
+		return true
+	})
+	if svc != "payments" {
+		t.Errorf("svc = %q, want payments", svc)
+	}
+}
+
+func TestNopLogger(t *testing.T) {
+	l := NopLogger()
+	l.InfoCtx(context.Background(), "discarded")
+	if got := l.WithAttrs(slog.String("k", "v")); got != l {
+		t.Errorf("NopLogger().WithAttrs() = %v, want the same logger", got)
+	}
+}
@@ -0,0 +1,111 @@
+package sloglog
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Headers HTTPMiddleware reads incoming trace IDs from, in order of preference
+const (
+	RequestIDHeader = "X-Request-ID"
+	TraceIDHeader   = "X-Trace-ID"
+)
+
+// HTTPMiddleware stores a trace ID in the request context and logs a summary of every request
+// with the default logger. The trace ID is taken from the X-Request-ID or X-Trace-ID header, or
+// generated when both are absent. The summaries are attributed to the call of HTTPMiddleware, as
+// no code of the application remains on the stack once the handler has returned
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return httpMiddleware(next, DefaultLogger, callerSource(1))
+}
+
+// HTTPMiddleware is the package-level HTTPMiddleware logging the summaries with l
+func (l *Logger) HTTPMiddleware(next http.Handler) http.Handler {
+	return httpMiddleware(next, func() *Logger { return l }, callerSource(1))
+}
+
+// httpMiddleware logs the summaries with the logger returned by logger at the time of each
+// request, attributing them to source
+func httpMiddleware(next http.Handler, logger func() *Logger, source string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		traceID := r.Header.Get(RequestIDHeader)
+		if traceID == "" {
+			traceID = r.Header.Get(TraceIDHeader)
+		}
+		if traceID == "" {
+			traceID = uuid.New().String()
+		}
+
+		ctx := context.WithValue(r.Context(), TraceIDContextKey, traceID)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		logger().logWithSource(ctx, source, slog.LevelInfo, "http request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Duration("duration", time.Since(start)),
+		)
+	})
+}
+
+// statusRecorder wraps http.ResponseWriter to capture the response status code and size
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	written     int
+	wroteHeader bool
+}
+
+// WriteHeader records the status code before forwarding it
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Write marks the header as written with the implicit 200 status
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.written += n
+	return n, err
+}
+
+// Status returns the response status code
+func (r *statusRecorder) Status() int {
+	return r.status
+}
+
+// BytesWritten returns the number of response body bytes written so far
+func (r *statusRecorder) BytesWritten() int {
+	return r.written
+}
+
+// Flush sends buffered data to the client when the underlying ResponseWriter supports it,
+// so that streaming handlers keep working behind the middleware
+func (r *statusRecorder) Flush() {
+	r.wroteHeader = true
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Hijack takes over the connection when the underlying ResponseWriter supports it, e.g. for
+// WebSocket upgrades, and returns an error wrapping http.ErrNotSupported otherwise
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the underlying ResponseWriter for use with http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,106 @@
+package sloglog
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	logger, buf := newBufferLogger()
+	useDefaultLogger(t, logger)
+
+	var seen []string
+	handler := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, GetTraceID(r.Context()))
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/brew", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodPost, "/pour", nil)
+	req.Header.Set(TraceIDHeader, "trace-2")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if len(seen) != 3 || seen[0] != "req-1" || seen[1] != "trace-2" || seen[2] == "" {
+		t.Fatalf("trace IDs seen by the handler = %q, want req-1, trace-2 and a generated one", seen)
+	}
+
+	records := lines(buf)
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3:\n%s", len(records), buf)
+	}
+	for i, record := range records {
+		for _, want := range []string{"http request", "status=418", "trace_id=" + seen[i], "duration="} {
+			if !strings.Contains(record, want) {
+				t.Errorf("record %d = %q, want %s", i, record, want)
+			}
+		}
+		if !strings.Contains(record, "middleware_test.go:") {
+			t.Errorf("record %d = %q, want the call of HTTPMiddleware as source", i, record)
+		}
+	}
+	if !strings.Contains(records[1], "method=POST") || !strings.Contains(records[1], "path=/pour") {
+		t.Errorf("record 1 = %q, want POST /pour", records[1])
+	}
+}
+
+func TestLoggerHTTPMiddleware(t *testing.T) {
+	logger, buf := newBufferLogger()
+	def, defBuf := newBufferLogger()
+	useDefaultLogger(t, def)
+
+	handler := logger.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	if out := buf.String(); !strings.Contains(out, "path=/users") || !strings.Contains(out, "middleware_test.go:") {
+		t.Errorf("logger output = %q, want the summary attributed to the call of HTTPMiddleware", out)
+	}
+	if defBuf.Len() != 0 {
+		t.Errorf("default logger output = %q, want none", defBuf)
+	}
+}
+
+// hijackRecorder is a ResponseRecorder supporting Hijack
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+	return nil, nil, nil
+}
+
+func TestHTTPMiddlewareFlushAndHijack(t *testing.T) {
+	logger, _ := newBufferLogger()
+	var flushErr, hijackErr error
+	handler := logger.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		flushErr = rc.Flush()
+		_, _, hijackErr = rc.Hijack()
+	}))
+
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if flushErr != nil || !rec.Flushed {
+		t.Errorf("Flush = %v, flushed %t, want it forwarded", flushErr, rec.Flushed)
+	}
+	if hijackErr != nil || !rec.hijacked {
+		t.Errorf("Hijack = %v, hijacked %t, want it forwarded", hijackErr, rec.hijacked)
+	}
+
+	// A plain ResponseRecorder cannot be hijacked
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !errors.Is(hijackErr, http.ErrNotSupported) {
+		t.Errorf("Hijack = %v, want http.ErrNotSupported", hijackErr)
+	}
+}
@@ -0,0 +1,107 @@
+package sloglog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// MultiHandler dispatches every record to all of its handlers
+type MultiHandler struct {
+	handlers []slog.Handler
+}
+
+// NewMultiHandler creates a handler that forwards records to each of handlers
+func NewMultiHandler(handlers ...slog.Handler) *MultiHandler {
+	return &MultiHandler{handlers: handlers}
+}
+
+// Enabled reports whether any of the handlers handles records at level
+func (h *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle forwards a copy of the Record to every handler that handles its level
+func (h *MultiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, r.Level) {
+			errs = append(errs, handler.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a new MultiHandler whose handlers all include attrs
+func (h *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return NewMultiHandler(handlers...)
+}
+
+// WithGroup returns a new MultiHandler whose handlers all open the given group
+func (h *MultiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return NewMultiHandler(handlers...)
+}
+
+// Tee returns a child of l that also writes every record to w, formatted like the console output
+// of l. Records logged through l itself are not written to w
+func Tee(l *Logger, w io.Writer) *Logger {
+	handler := l.logger.Handler()
+	ungrouped := handler
+	if l.ungrouped != nil {
+		ungrouped = l.ungrouped
+	}
+
+	// The tee is derived from the handler before the groups of l, which are replayed on it
+	// together with their attributes
+	var tee slog.Handler
+	if template := consoleHandler(ungrouped); template != nil {
+		h := *template
+		h.writer = w
+		h.color = isTerminal(w)
+		tee = &h
+	} else {
+		opts := &slog.HandlerOptions{AddSource: l.addSource, Level: l.leveler()}
+		tee = NewCustomHandler(w, opts, l.addSource)
+		if len(l.attrs) > 0 && len(l.attrs[0]) > 0 {
+			tee = tee.WithAttrs(l.attrs[0])
+		}
+	}
+
+	child := l.clone()
+	child.logger = slog.New(NewMultiHandler(handler, l.withGroups(tee)))
+	if l.ungrouped != nil {
+		child.ungrouped = NewMultiHandler(l.ungrouped, tee)
+	}
+	return child
+}
+
+// consoleHandler returns the first CustomHandler found in h, or nil if there is none
+func consoleHandler(h slog.Handler) *CustomHandler {
+	switch h := h.(type) {
+	case *CustomHandler:
+		return h
+	case *SplitHandler:
+		return consoleHandler(h.low)
+	case *MultiHandler:
+		for _, handler := range h.handlers {
+			if ch := consoleHandler(handler); ch != nil {
+				return ch
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package sloglog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestTee(t *testing.T) {
+	logger, orig := newBufferLogger(WithSource(false))
+	var captured bytes.Buffer
+	tee := Tee(logger, &captured)
+
+	tee.Info("migrating", slog.Int("step", 1))
+	logger.Info("outside")
+
+	for name, buf := range map[string]*bytes.Buffer{"original": orig, "tee": &captured} {
+		if !strings.Contains(buf.String(), "migrating step=1") {
+			t.Errorf("%s output %q does not contain the tee'd record", name, buf)
+		}
+	}
+	if strings.Contains(captured.String(), "outside") {
+		t.Errorf("tee output %q contains a record of the original logger", captured.String())
+	}
+}
+
+func TestTeeJSON(t *testing.T) {
+	logger, orig := newBufferLogger(WithFormat(FormatJSON))
+	var captured bytes.Buffer
+
+	Tee(logger, &captured).Info("migrating")
+
+	for _, buf := range []*bytes.Buffer{orig, &captured} {
+		if record := decodeJSONLines(t, buf); len(record) != 1 || record[0]["msg"] != "migrating" {
+			t.Errorf("JSON output = %v, want one record", record)
+		}
+	}
+}
+
+func TestTeeOpaqueHandlerReplaysGroupsAndAttrs(t *testing.T) {
+	var orig, captured bytes.Buffer
+	logger := NewLoggerWithHandler(slog.NewTextHandler(&orig, nil)).
+		With(slog.Int("a", 1)).
+		Group("g").
+		With(slog.Int("b", 2)).
+		Group("h")
+
+	Tee(logger, &captured).Info("migrating", slog.Int("c", 3))
+
+	for _, want := range []string{"a=1", "g.b=2", "g.h.c=3"} {
+		if !strings.Contains(captured.String(), want) {
+			t.Errorf("tee output %q does not contain %s", captured.String(), want)
+		}
+	}
+	if strings.Contains(captured.String(), "g.a=1") || strings.Contains(captured.String(), "g.h.b=2") {
+		t.Errorf("tee output %q nests attributes under groups opened after them", captured.String())
+	}
+}
@@ -0,0 +1,250 @@
+package sloglog
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Option configures a Logger created by NewLogger or InitLogger
+type Option func(*loggerConfig)
+
+// loggerConfig holds the settings collected from Options
+type loggerConfig struct {
+	level       slog.Leveler
+	writer      io.Writer
+	addSource   bool
+	color       *bool        // nil means auto-detect from the writer
+	colors      *ColorScheme // nil means DefaultColorScheme
+	format      Format
+	timeFormat  string
+	timeKey     string
+	levelKey    string
+	messageKey  string
+	treeStyle   TreeStyle
+	errorKey    string
+	traceIDKey  string
+	callerSkip  int
+	goroutineID bool
+	exitFunc    func(int)
+	panicFunc   func(string)
+
+	contextDeadline   bool
+	deadlineThreshold time.Duration
+
+	extractors []ContextExtractor
+
+	redactedKeys []string
+	masker       Masker
+
+	// Records at or above splitLevel go to highWriter when it is set
+	splitLevel slog.Level
+	highWriter io.Writer
+}
+
+// defaultLoggerConfig returns the settings used when no options are given
+func defaultLoggerConfig() loggerConfig {
+	return loggerConfig{
+		level:      slog.LevelInfo,
+		writer:     os.Stdout,
+		addSource:  true,
+		errorKey:   "error",
+		traceIDKey: TraceIDKey,
+		exitFunc:   os.Exit,
+		panicFunc:  func(msg string) { panic(msg) },
+
+		deadlineThreshold: 5 * time.Second,
+	}
+}
+
+// newHandler creates a console handler writing to w with the configured settings
+func (c *loggerConfig) newHandler(w io.Writer) *CustomHandler {
+	opts := &slog.HandlerOptions{
+		AddSource: c.addSource,
+		Level:     c.level,
+	}
+	h := NewCustomHandler(w, opts, c.addSource)
+	if c.color != nil {
+		h.color = *c.color
+	}
+	if c.colors != nil {
+		h.colors = *c.colors
+	}
+	h.format = c.format
+	h.timeFormat = c.timeFormat
+	h.timeKey, h.levelKey, h.messageKey = c.timeKey, c.levelKey, c.messageKey
+	return h
+}
+
+// WithLevel sets the minimum level of records the logger emits
+func WithLevel(level slog.Leveler) Option {
+	return func(c *loggerConfig) {
+		c.level = level
+	}
+}
+
+// WithWriter sets the destination of console output (default: os.Stdout)
+func WithWriter(w io.Writer) Option {
+	return func(c *loggerConfig) {
+		c.writer = w
+	}
+}
+
+// WithLevelSplitWriter sends records below the given level to lowWriter and all others to highWriter
+func WithLevelSplitWriter(below slog.Level, lowWriter, highWriter io.Writer) Option {
+	return func(c *loggerConfig) {
+		c.splitLevel = below
+		c.writer = lowWriter
+		c.highWriter = highWriter
+	}
+}
+
+// WithSource enables or disables source location tracking
+func WithSource(enabled bool) Option {
+	return func(c *loggerConfig) {
+		c.addSource = enabled
+	}
+}
+
+// WithColor forces ANSI colors on or off regardless of terminal detection
+func WithColor(enabled bool) Option {
+	return func(c *loggerConfig) {
+		c.color = &enabled
+	}
+}
+
+// WithColorScheme sets the ANSI escape sequences used for the levels when colors are enabled
+func WithColorScheme(cs ColorScheme) Option {
+	return func(c *loggerConfig) {
+		c.colors = &cs
+	}
+}
+
+// WithFormat sets the output format of console records (default: FormatText)
+func WithFormat(format Format) Option {
+	return func(c *loggerConfig) {
+		c.format = format
+	}
+}
+
+// WithTimestampFormat sets the Go time layout of timestamps in console and file output, e.g.
+// time.RFC3339. TimestampUnixMilli emits the Unix time in milliseconds (default: a format-specific layout)
+func WithTimestampFormat(layout string) Option {
+	return func(c *loggerConfig) {
+		c.timeFormat = layout
+	}
+}
+
+// WithMessageKey sets the key of the message in JSON output (default: "msg").
+// Text output is positional and has no keys
+func WithMessageKey(key string) Option {
+	return func(c *loggerConfig) {
+		c.messageKey = key
+	}
+}
+
+// WithLevelKey sets the key of the level in JSON output (default: "level")
+func WithLevelKey(key string) Option {
+	return func(c *loggerConfig) {
+		c.levelKey = key
+	}
+}
+
+// WithTimeKey sets the key of the timestamp in JSON output (default: "time")
+func WithTimeKey(key string) Option {
+	return func(c *loggerConfig) {
+		c.timeKey = key
+	}
+}
+
+// WithTreeStyle sets the characters drawing the attributes of file entries (default: TreeStyleUnicode)
+func WithTreeStyle(style TreeStyle) Option {
+	return func(c *loggerConfig) {
+		c.treeStyle = style
+	}
+}
+
+// WithErrorKey sets the attribute key used by WithError and ErrorCtxErr (default: "error")
+func WithErrorKey(key string) Option {
+	return func(c *loggerConfig) {
+		c.errorKey = key
+	}
+}
+
+// WithTraceIDKey sets the attribute key of trace IDs taken from the context (default: "trace_id").
+// The context key is unaffected
+func WithTraceIDKey(key string) Option {
+	return func(c *loggerConfig) {
+		c.traceIDKey = key
+	}
+}
+
+// WithContextExtractor adds fn to the functions that derive attributes from the context of every
+// record. Attributes whose key is already present, such as an explicit trace_id, are skipped
+func WithContextExtractor(fn ContextExtractor) Option {
+	return func(c *loggerConfig) {
+		c.extractors = append(c.extractors, fn)
+	}
+}
+
+// WithCallerSkipOption skips n additional stack frames when attributing the source,
+// for use by libraries that wrap Logger
+func WithCallerSkipOption(n int) Option {
+	return func(c *loggerConfig) {
+		c.callerSkip = n
+	}
+}
+
+// WithGoroutineID adds the ID of the logging goroutine as goroutine_id to every record
+func WithGoroutineID(enabled bool) Option {
+	return func(c *loggerConfig) {
+		c.goroutineID = enabled
+	}
+}
+
+// WithRedactedKeys masks the value of attributes whose key matches one of keys, ignoring case,
+// including attributes nested in groups. Values are replaced with "[REDACTED]" unless a Masker is set
+func WithRedactedKeys(keys ...string) Option {
+	return func(c *loggerConfig) {
+		c.redactedKeys = append(c.redactedKeys, keys...)
+	}
+}
+
+// WithMasker sets the function computing the replacement of redacted values (default: "[REDACTED]")
+func WithMasker(m Masker) Option {
+	return func(c *loggerConfig) {
+		c.masker = m
+	}
+}
+
+// WithContextDeadline adds the time left until the deadline of the context, in milliseconds, as
+// deadline_remaining_ms to records whose deadline is within the threshold set by
+// WithContextDeadlineThreshold. The value is negative once the deadline has passed
+func WithContextDeadline(enabled bool) Option {
+	return func(c *loggerConfig) {
+		c.contextDeadline = enabled
+	}
+}
+
+// WithContextDeadlineThreshold sets how close the deadline must be for WithContextDeadline to
+// add it. Zero adds it for every context with a deadline (default: 5 seconds)
+func WithContextDeadlineThreshold(d time.Duration) Option {
+	return func(c *loggerConfig) {
+		c.deadlineThreshold = d
+	}
+}
+
+// WithExitFunc sets the function called by Fatal after logging (default: os.Exit)
+func WithExitFunc(fn func(code int)) Option {
+	return func(c *loggerConfig) {
+		c.exitFunc = fn
+	}
+}
+
+// WithPanicFunc sets the function called by Panic after logging (default: the built-in panic)
+func WithPanicFunc(fn func(msg string)) Option {
+	return func(c *loggerConfig) {
+		c.panicFunc = fn
+	}
+}
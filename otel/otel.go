@@ -0,0 +1,37 @@
+// Package otel adds OpenTelemetry trace extraction to sloglog loggers.
+//
+// It lives in its own package so that only programs using OpenTelemetry depend on it
+package otel
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aeternitas-infinita/sloglog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithOTelTraceExtraction adds trace_id and span_id attributes from the OpenTelemetry span
+// stored in the context of every record. When the context also holds a trace or span ID
+// stored by sloglog, e.g. under sloglog.TraceIDContextKey, those take priority and neither ID
+// of the span is added, so that a record never mixes the IDs of two traces
+func WithOTelTraceExtraction() sloglog.Option {
+	return sloglog.WithContextExtractor(extractSpanContext)
+}
+
+// extractSpanContext returns the trace and span IDs of the span in ctx, if it is valid and
+// ctx holds no sloglog IDs
+func extractSpanContext(ctx context.Context) []slog.Attr {
+	if sloglog.GetTraceID(ctx) != "" || sloglog.GetSpanID(ctx) != "" {
+		return nil
+	}
+	spanCtx := trace.SpanFromContext(ctx).SpanContext()
+	if !spanCtx.IsValid() {
+		return nil
+	}
+
+	return []slog.Attr{
+		slog.String(sloglog.TraceIDKey, spanCtx.TraceID().String()),
+		slog.String(sloglog.SpanIDKey, spanCtx.SpanID().String()),
+	}
+}
@@ -0,0 +1,113 @@
+package otel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aeternitas-infinita/sloglog"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// spanContext returns a context holding a span started by a no-op tracer within a sampled remote parent
+func spanContext(t *testing.T) (context.Context, trace.SpanContext) {
+	t.Helper()
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), parent)
+	ctx, span := noop.NewTracerProvider().Tracer("test").Start(ctx, "operation")
+	t.Cleanup(func() { span.End() })
+	return ctx, span.SpanContext()
+}
+
+// decodeRecord decodes the single JSON record in buf
+func decodeRecord(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid JSON record %q: %v", buf.String(), err)
+	}
+	return record
+}
+
+func TestWithOTelTraceExtraction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := sloglog.NewLogger(sloglog.WithWriter(&buf), sloglog.WithFormat(sloglog.FormatJSON), WithOTelTraceExtraction())
+	ctx, spanCtx := spanContext(t)
+
+	logger.InfoCtx(ctx, "traced")
+
+	record := decodeRecord(t, &buf)
+	if got, want := record["trace_id"], spanCtx.TraceID().String(); got != want {
+		t.Errorf("trace_id = %v, want %s", got, want)
+	}
+	if got, want := record["span_id"], spanCtx.SpanID().String(); got != want {
+		t.Errorf("span_id = %v, want %s", got, want)
+	}
+}
+
+func TestExplicitIDsTakePriority(t *testing.T) {
+	tests := []struct {
+		name string
+		key  any
+	}{
+		{"trace ID", sloglog.TraceIDContextKey},
+		{"span ID", sloglog.SpanIDContextKey},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		logger := sloglog.NewLogger(sloglog.WithWriter(&buf), sloglog.WithFormat(sloglog.FormatJSON), WithOTelTraceExtraction())
+		ctx, spanCtx := spanContext(t)
+		ctx = context.WithValue(ctx, tt.key, "explicit")
+
+		logger.InfoCtx(ctx, "traced")
+
+		record := decodeRecord(t, &buf)
+		for _, key := range []string{"trace_id", "span_id"} {
+			if got := record[key]; got == spanCtx.TraceID().String() || got == spanCtx.SpanID().String() {
+				t.Errorf("explicit %s: %s = %v taken from the span", tt.name, key, got)
+			}
+		}
+		if n := strings.Count(buf.String(), `"explicit"`); n != 1 {
+			t.Errorf("explicit %s: record %s, want the explicit ID once", tt.name, buf.String())
+		}
+	}
+}
+
+func TestWithTraceIDKey(t *testing.T) {
+	var buf bytes.Buffer
+	logger := sloglog.NewLogger(sloglog.WithWriter(&buf), sloglog.WithFormat(sloglog.FormatJSON),
+		sloglog.WithTraceIDKey("traceId"), WithOTelTraceExtraction())
+	ctx, spanCtx := spanContext(t)
+
+	logger.InfoCtx(ctx, "traced")
+	record := decodeRecord(t, &buf)
+	if got, want := record["traceId"], spanCtx.TraceID().String(); got != want || record["trace_id"] != nil {
+		t.Errorf("record = %v, want the trace ID %s under traceId only", record, want)
+	}
+
+	buf.Reset()
+	logger.InfoCtx(context.WithValue(ctx, sloglog.TraceIDContextKey, "explicit"), "traced")
+	if n := strings.Count(buf.String(), `"traceId"`); n != 1 || !strings.Contains(buf.String(), `"traceId":"explicit"`) {
+		t.Errorf("record %s, want the explicit trace ID once under traceId", buf.String())
+	}
+}
+
+func TestNoSpan(t *testing.T) {
+	var buf bytes.Buffer
+	logger := sloglog.NewLogger(sloglog.WithWriter(&buf), sloglog.WithFormat(sloglog.FormatJSON), WithOTelTraceExtraction())
+
+	logger.InfoCtx(context.Background(), "untraced")
+
+	record := decodeRecord(t, &buf)
+	if _, ok := record["trace_id"]; ok {
+		t.Errorf("record %v has a trace_id without a span", record)
+	}
+}
@@ -0,0 +1,149 @@
+package sloglog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParsedRecord is a record read back from a log file by ParseLogFile
+type ParsedRecord struct {
+	Timestamp time.Time
+	Level     slog.Level
+	Message   string
+	Attrs     map[string]string
+}
+
+// attrPrefixes are the attribute line prefixes of all tree styles, see formatLogEntry
+var attrPrefixes = func() []string {
+	var prefixes []string
+	for _, style := range []TreeStyle{TreeStyleUnicode, TreeStyleASCII} {
+		branch, last := style.prefixes()
+		prefixes = append(prefixes, branch, last)
+	}
+	return prefixes
+}()
+
+// cutAttrPrefix returns line without its attribute prefix, reporting whether it had one
+func cutAttrPrefix(line string) (string, bool) {
+	for _, prefix := range attrPrefixes {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			return rest, true
+		}
+	}
+	return line, false
+}
+
+// ParseLogFile reads records in the file format written by file logging. Attributes are
+// returned as their formatted string values; lines that belong to no attribute, such as the
+// continuation of a multi-line value, are appended to the preceding value or message.
+// On a malformed entry the records parsed so far are returned with the error
+func ParseLogFile(r io.Reader) ([]ParsedRecord, error) {
+	var records []ParsedRecord
+	var lastKey string // key of the last attribute of the current record, "" for the message
+
+	br := bufio.NewReader(r)
+	for lineNum := 1; ; lineNum++ {
+		line, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return records, err
+		}
+		if line == "" && err != nil {
+			return records, nil
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		attr, isAttr := cutAttrPrefix(line)
+
+		// Only a line of the full header shape starts a record, so that continuation lines
+		// beginning with "[" stay part of the preceding value
+		var rec ParsedRecord
+		var perr error
+		if strings.HasPrefix(line, "[") {
+			rec, perr = parseMainLine(line)
+		}
+
+		switch {
+		case strings.HasPrefix(line, "[") && perr == nil:
+			records = append(records, rec)
+			lastKey = ""
+
+		case len(records) == 0:
+			if perr != nil {
+				return records, fmt.Errorf("line %d: %w", lineNum, perr)
+			}
+			if line != "" {
+				return records, fmt.Errorf("line %d: expected a record header", lineNum)
+			}
+
+		case isAttr:
+			key, value, ok := strings.Cut(attr, ": ")
+			if !ok {
+				return records, fmt.Errorf("line %d: malformed attribute %q", lineNum, line)
+			}
+			records[len(records)-1].Attrs[key] = value
+			lastKey = key
+
+		default:
+			rec := &records[len(records)-1]
+			if lastKey == "" {
+				rec.Message += "\n" + line
+			} else {
+				rec.Attrs[lastKey] += "\n" + line
+			}
+		}
+
+		if err != nil {
+			return records, nil
+		}
+	}
+}
+
+// parseMainLine parses the "[timestamp] LEVEL | message" line starting a record
+func parseMainLine(line string) (ParsedRecord, error) {
+	end := strings.Index(line, "] ")
+	if end < 0 {
+		return ParsedRecord{}, fmt.Errorf("malformed record header %q", line)
+	}
+	levelName, msg, ok := strings.Cut(line[end+2:], " | ")
+	if !ok {
+		return ParsedRecord{}, fmt.Errorf("malformed record header %q", line)
+	}
+
+	ts, err := parseTimestamp(line[1:end])
+	if err != nil {
+		return ParsedRecord{}, err
+	}
+	level, err := ParseLevel(levelName)
+	if err != nil {
+		// Levels between the named ones are written like "INFO+2"
+		if uerr := level.UnmarshalText([]byte(levelName)); uerr != nil {
+			return ParsedRecord{}, err
+		}
+	}
+
+	return ParsedRecord{
+		Timestamp: ts,
+		Level:     level,
+		Message:   msg,
+		Attrs:     map[string]string{},
+	}, nil
+}
+
+// parseTimestamp parses a file entry timestamp in the default layout, RFC 3339 or Unix milliseconds
+func parseTimestamp(s string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05.000", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", s)
+}
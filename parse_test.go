@@ -0,0 +1,134 @@
+package sloglog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLogFileRoundTrip(t *testing.T) {
+	dir := enableTestFileLogging(t, FileLoggerOptions{})
+
+	var written []slog.Record
+	logger, _ := newBufferLogger(WithLevel(slog.LevelDebug), WithSource(false))
+	logger = logger.WithHooks(nil, func(r slog.Record) slog.Record {
+		written = append(written, r.Clone())
+		return r
+	})
+
+	levels := []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError, slog.LevelInfo + 2}
+	for i := range 50 {
+		var attrs []slog.Attr
+		switch i % 3 {
+		case 1:
+			attrs = []slog.Attr{slog.Int("i", i)}
+		case 2:
+			attrs = []slog.Attr{
+				slog.Int("i", i),
+				slog.String("note", "key: value"),
+				slog.Duration("elapsed", time.Duration(i)*time.Millisecond),
+			}
+		}
+		logger.LogAttrs(context.Background(), levels[i%len(levels)], fmt.Sprintf("record %d", i), attrs...)
+	}
+	flushFileLogger()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil || len(paths) != 1 {
+		t.Fatalf("log files = %v (%v), want one", paths, err)
+	}
+	f, err := os.Open(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	parsed, err := ParseLogFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != len(written) {
+		t.Fatalf("parsed %d records, want %d", len(parsed), len(written))
+	}
+	for i, want := range written {
+		got := parsed[i]
+		if !got.Timestamp.Equal(want.Time.Truncate(time.Millisecond)) || got.Level != want.Level || got.Message != want.Message {
+			t.Errorf("record %d = %v %v %q, want %v %v %q", i, got.Timestamp, got.Level, got.Message,
+				want.Time, want.Level, want.Message)
+		}
+
+		wantAttrs := map[string]string{}
+		want.Attrs(func(a slog.Attr) bool {
+			wantAttrs[a.Key] = a.Value.String()
+			return true
+		})
+		if fmt.Sprint(got.Attrs) != fmt.Sprint(wantAttrs) {
+			t.Errorf("record %d attrs = %v, want %v", i, got.Attrs, wantAttrs)
+		}
+	}
+}
+
+func TestParseLogFile(t *testing.T) {
+	input := strings.Join([]string{
+		"[2026-03-14 09:00:00.125] INFO | single",
+		"  └─ user: alice",
+		"[2026-03-14 09:00:01.000] WARN | multi",
+		"  ├─ a: 1",
+		"  ├─ b: first line",
+		"second line",
+		"[not a header] INFO | continued",
+		"  └─ c: 3",
+		"[2026-03-14T09:00:02Z] ERROR | rfc3339 without attributes",
+		"[1773478803000] DEBUG | unix milliseconds",
+		"  +-- ascii: yes",
+		"",
+	}, "\n")
+
+	records, err := ParseLogFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("parsed %d records, want 4", len(records))
+	}
+
+	if r := records[0]; r.Level != slog.LevelInfo || r.Message != "single" || r.Attrs["user"] != "alice" ||
+		!r.Timestamp.Equal(time.Date(2026, 3, 14, 9, 0, 0, 125e6, time.Local)) {
+		t.Errorf("single-attribute record = %+v", r)
+	}
+	if r := records[1]; len(r.Attrs) != 3 || r.Attrs["b"] != "first line\nsecond line\n[not a header] INFO | continued" || r.Attrs["c"] != "3" {
+		t.Errorf("multi-attribute record attrs = %q", r.Attrs)
+	}
+	if r := records[2]; r.Level != slog.LevelError || len(r.Attrs) != 0 || !r.Timestamp.Equal(time.Date(2026, 3, 14, 9, 0, 2, 0, time.UTC)) {
+		t.Errorf("RFC 3339 record = %+v", r)
+	}
+	if r := records[3]; !r.Timestamp.Equal(time.UnixMilli(1773478803000)) || r.Attrs["ascii"] != "yes" {
+		t.Errorf("Unix milliseconds record = %+v", r)
+	}
+}
+
+func TestParseLogFileMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"attribute before header", "  └─ a: 1\n"},
+		{"bad timestamp", "[yesterday] INFO | msg\n"},
+		{"bad level", "[2026-03-14 09:00:00.000] LOUD | msg\n"},
+		{"missing separator", "[2026-03-14 09:00:00.000] INFO msg\n"},
+		{"attribute without value", "[2026-03-14 09:00:00.000] INFO | msg\n  └─ novalue\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseLogFile(strings.NewReader(tt.input)); err == nil {
+				t.Errorf("ParseLogFile(%q) succeeded, want an error", tt.input)
+			}
+		})
+	}
+}
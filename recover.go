@@ -0,0 +1,109 @@
+package sloglog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// RecoverAndLog calls fn and logs any panic it raises at error level with the panic value and
+// the full stack trace. When rethrow is true the panic is re-raised after logging.
+// A nil logger uses the default logger
+func RecoverAndLog(logger *Logger, fn func(), rethrow bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			logPanic(context.Background(), logger, v)
+			if rethrow {
+				panic(v)
+			}
+		}
+	}()
+	fn()
+}
+
+// RecoverMiddleware logs panics raised by net/http handlers. When rethrow is false the
+// client receives a 500 response instead. A nil logger uses the default logger
+func RecoverMiddleware(logger *Logger, rethrow bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				// http.ErrAbortHandler is used to abort a response on purpose
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				logPanic(r.Context(), logger, v)
+				if rethrow {
+					panic(v)
+				}
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RecoverFHMiddleware logs panics raised by fasthttp handlers. When rethrow is false the
+// client receives a 500 response instead. A nil logger uses the default logger
+func RecoverFHMiddleware(logger *Logger, rethrow bool) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+
+				logPanic(ctx, logger, v)
+				if rethrow {
+					panic(v)
+				}
+				ctx.Error(fasthttp.StatusMessage(fasthttp.StatusInternalServerError), fasthttp.StatusInternalServerError)
+			}()
+			next(ctx)
+		}
+	}
+}
+
+// logPanic logs a recovered panic value together with the stack of the panicking goroutine,
+// attributing the record to the frame that panicked
+func logPanic(ctx context.Context, logger *Logger, v any) {
+	if logger == nil {
+		logger = defaultLogger.Load()
+	}
+	logger.logWithSource(ctx, panicSource(), slog.LevelError, "recovered from panic",
+		slog.Any("panic", v),
+		slog.String("stack", string(debug.Stack())),
+	)
+}
+
+// panicSource returns the source of the frame that raised the panic being recovered: the first
+// frame outside the runtime below runtime.gopanic on the stack of the deferred call
+func panicSource() string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		if panicking && !strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("[%s:%d]", frame.File, frame.Line)
+		}
+		if frame.Function == "runtime.gopanic" {
+			panicking = true
+		}
+		if !more {
+			return ""
+		}
+	}
+}
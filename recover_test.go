@@ -0,0 +1,140 @@
+package sloglog
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// panicLine is set to the line of the panic raised by panicWithLine
+var panicLine int
+
+// panicWithLine panics with v, recording the line of the panic in panicLine
+func panicWithLine(v any) {
+	_, _, line, _ := runtime.Caller(0)
+	panicLine = line + 2
+	panic(v)
+}
+
+// assertPanicRecord checks that buf holds a single panic record with value want, the stack
+// and the panicking line as its source
+func assertPanicRecord(t *testing.T, records []map[string]any, want string) {
+	t.Helper()
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	record := records[0]
+	if record["level"] != "ERROR" || record["panic"] != want {
+		t.Errorf("record = %v, want an ERROR record with panic=%s", record, want)
+	}
+	if stack, _ := record["stack"].(string); !strings.Contains(stack, "panicWithLine") {
+		t.Errorf("stack %q does not contain the panicking function", stack)
+	}
+	if source, want := record["source"], fmt.Sprintf("recover_test.go:%d]", panicLine); !strings.HasSuffix(fmt.Sprint(source), want) {
+		t.Errorf("source = %v, want the panicking line ending in %s", source, want)
+	}
+}
+
+func TestRecoverAndLogRethrow(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+
+	recovered := func() (v any) {
+		defer func() { v = recover() }()
+		RecoverAndLog(logger, func() { panicWithLine("boom") }, true)
+		return nil
+	}()
+
+	if recovered != "boom" {
+		t.Fatalf("recovered %v outside RecoverAndLog, want the rethrown panic", recovered)
+	}
+	assertPanicRecord(t, decodeJSONLines(t, buf), "boom")
+}
+
+func TestRecoverAndLogSwallow(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+
+	recovered := func() (v any) {
+		defer func() { v = recover() }()
+		RecoverAndLog(logger, func() { panicWithLine("swallowed") }, false)
+		return nil
+	}()
+
+	if recovered != nil {
+		t.Fatalf("recovered %v outside RecoverAndLog, want no panic", recovered)
+	}
+	assertPanicRecord(t, decodeJSONLines(t, buf), "swallowed")
+}
+
+func TestRecoverAndLogRuntimePanic(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+
+	RecoverAndLog(logger, func() {
+		var m map[string]int
+		_, _, line, _ := runtime.Caller(0)
+		panicLine = line + 2
+		m["nil map"] = 1
+	}, false)
+
+	records := decodeJSONLines(t, buf)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if source, want := fmt.Sprint(records[0]["source"]), fmt.Sprintf("recover_test.go:%d]", panicLine); !strings.HasSuffix(source, want) {
+		t.Errorf("source = %s, want the faulting line ending in %s", source, want)
+	}
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+	handler := RecoverMiddleware(logger, false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panicWithLine("handler failed")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	assertPanicRecord(t, decodeJSONLines(t, buf), "handler failed")
+}
+
+func TestRecoverMiddlewareAbortHandler(t *testing.T) {
+	logger, buf := newBufferLogger()
+	handler := RecoverMiddleware(logger, false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	recovered := func() (v any) {
+		defer func() { v = recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		return nil
+	}()
+
+	if recovered != http.ErrAbortHandler {
+		t.Errorf("recovered %v, want http.ErrAbortHandler to propagate", recovered)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("aborted request was logged: %q", buf)
+	}
+}
+
+func TestRecoverFHMiddleware(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+	handler := RecoverFHMiddleware(logger, false)(func(ctx *fasthttp.RequestCtx) {
+		panicWithLine("fasthttp handler failed")
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	handler(ctx)
+
+	if code := ctx.Response.StatusCode(); code != fasthttp.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", code)
+	}
+	assertPanicRecord(t, decodeJSONLines(t, buf), "fasthttp handler failed")
+}
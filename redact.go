@@ -0,0 +1,67 @@
+package sloglog
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// redactedValue replaces the value of redacted attributes when no Masker is set
+const redactedValue = "[REDACTED]"
+
+// Masker returns the replacement for the value of a redacted attribute, e.g. to keep
+// the last four digits of a card number
+type Masker func(key, value string) string
+
+// redactor masks the values of attributes with sensitive keys
+type redactor struct {
+	keys map[string]struct{} // lower-cased
+	mask Masker
+}
+
+// newRedactor returns a redactor for keys, or nil if there are none
+func newRedactor(keys []string, mask Masker) *redactor {
+	if len(keys) == 0 {
+		return nil
+	}
+	if mask == nil {
+		mask = func(string, string) string { return redactedValue }
+	}
+
+	r := &redactor{keys: make(map[string]struct{}, len(keys)), mask: mask}
+	for _, key := range keys {
+		r.keys[strings.ToLower(key)] = struct{}{}
+	}
+	return r
+}
+
+// record returns a copy of record with sensitive attributes masked, leaving record untouched
+func (r *redactor) record(record slog.Record) slog.Record {
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(r.attr(a))
+		return true
+	})
+	return redacted
+}
+
+// attrs returns a copy of attrs with sensitive attributes masked
+func (r *redactor) attrs(attrs []slog.Attr) []slog.Attr {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = r.attr(a)
+	}
+	return redacted
+}
+
+// attr masks a if its key is sensitive, descending into groups
+func (r *redactor) attr(a slog.Attr) slog.Attr {
+	if _, ok := r.keys[strings.ToLower(a.Key)]; ok {
+		return slog.String(a.Key, r.mask(a.Key, a.Value.Resolve().String()))
+	}
+
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(r.attrs(v.Group())...)}
+	}
+	return a
+}
@@ -0,0 +1,84 @@
+package sloglog
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithRedactedKeys(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON), WithRedactedKeys("password", "TOKEN", "authorization"))
+
+	logger.With(slog.String("Authorization", "Bearer abc")).Info("login",
+		slog.String("user", "alice"),
+		slog.String("Password", "hunter2"),
+		slog.Group("request", slog.String("token", "t-123"), slog.String("path", "/login")),
+	)
+
+	records := decodeJSONLines(t, buf)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	record := records[0]
+	request, _ := record["request"].(map[string]any)
+	redacted := map[string]any{
+		"Authorization": record["Authorization"],
+		"Password":      record["Password"],
+		"request.token": request["token"],
+	}
+	for key, got := range redacted {
+		if got != redactedValue {
+			t.Errorf("%s = %v, want %s", key, got, redactedValue)
+		}
+	}
+	if record["user"] != "alice" || request["path"] != "/login" {
+		t.Errorf("record = %v, want other fields untouched", record)
+	}
+	if strings.Contains(buf.String(), "hunter2") || strings.Contains(buf.String(), "t-123") {
+		t.Errorf("output contains a sensitive value: %s", buf)
+	}
+}
+
+func TestWithMasker(t *testing.T) {
+	lastFour := func(key, value string) string {
+		return strings.Repeat("*", len(value)-4) + value[len(value)-4:]
+	}
+	logger, buf := newBufferLogger(WithFormat(FormatJSON), WithRedactedKeys("card"), WithMasker(lastFour))
+
+	logger.Info("payment", slog.String("card", "4111111111111111"))
+
+	if got := decodeJSONLines(t, buf)[0]["card"]; got != "************1111" {
+		t.Errorf("card = %v, want the last four digits only", got)
+	}
+}
+
+func TestRedactorLeavesRecordUntouched(t *testing.T) {
+	r := newRedactor([]string{"password"}, nil)
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+	record.AddAttrs(slog.String("password", "hunter2"), slog.Group("g", slog.String("password", "nested")))
+
+	redacted := r.record(record)
+
+	var original, masked []string
+	record.Attrs(func(a slog.Attr) bool {
+		original = appendTextAttr(original, "", a)
+		return true
+	})
+	redacted.Attrs(func(a slog.Attr) bool {
+		masked = appendTextAttr(masked, "", a)
+		return true
+	})
+	if got, want := strings.Join(original, " "), "password=hunter2 g.password=nested"; got != want {
+		t.Errorf("original record = %s, want %s", got, want)
+	}
+	if got, want := strings.Join(masked, " "), "password=[REDACTED] g.password=[REDACTED]"; got != want {
+		t.Errorf("redacted record = %s, want %s", got, want)
+	}
+}
+
+func TestNewRedactorWithoutKeys(t *testing.T) {
+	if r := newRedactor(nil, nil); r != nil {
+		t.Errorf("newRedactor(nil) = %v, want nil so that nothing is copied", r)
+	}
+}
@@ -0,0 +1,90 @@
+package sloglog
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// Registry hands out named child loggers whose levels can be configured independently
+type Registry struct {
+	mu      sync.Mutex
+	parent  *Logger
+	entries map[string]*registryEntry
+}
+
+// registryEntry holds a named logger and its level, which implements slog.Leveler
+type registryEntry struct {
+	logger     *Logger
+	parent     slog.Leveler
+	level      slog.LevelVar
+	configured atomic.Bool
+}
+
+// Level returns the configured level, or the parent's level when none was configured
+func (e *registryEntry) Level() slog.Level {
+	if e.configured.Load() {
+		return e.level.Level()
+	}
+	return e.parent.Level()
+}
+
+// defaultRegistry backs GetLogger and ConfigureLogger
+var defaultRegistry = NewRegistry(nil)
+
+// NewRegistry creates a registry whose loggers derive from parent, or from the default
+// logger at the time of their first use when parent is nil
+func NewRegistry(parent *Logger) *Registry {
+	return &Registry{
+		parent:  parent,
+		entries: make(map[string]*registryEntry),
+	}
+}
+
+// Get returns the logger with the given name, creating it on first use. Every record it
+// emits carries logger=<name>, and it inherits its parent's level until Configure is called
+func (r *Registry) Get(name string) *Logger {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e := r.entry(name)
+	if e.logger == nil {
+		parent := r.parent
+		if parent == nil {
+			parent = defaultLogger.Load()
+		}
+		e.parent = parent.leveler()
+		e.logger = parent.withLeveler(e).With(slog.String("logger", name))
+	}
+	return e.logger
+}
+
+// Configure sets the level of the named logger, overriding the inherited level
+func (r *Registry) Configure(name string, level slog.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e := r.entry(name)
+	e.level.Set(level)
+	e.configured.Store(true)
+}
+
+// entry returns the entry for name, creating it if needed. r.mu must be held
+func (r *Registry) entry(name string) *registryEntry {
+	e, ok := r.entries[name]
+	if !ok {
+		e = &registryEntry{}
+		r.entries[name] = e
+	}
+	return e
+}
+
+// GetLogger returns the named logger from the default registry
+func GetLogger(name string) *Logger {
+	return defaultRegistry.Get(name)
+}
+
+// ConfigureLogger sets the level of the named logger in the default registry
+func ConfigureLogger(name string, level slog.Level) {
+	defaultRegistry.Configure(name, level)
+}
@@ -0,0 +1,87 @@
+package sloglog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegistryGet(t *testing.T) {
+	parent, buf := newBufferLogger()
+	r := NewRegistry(parent)
+
+	db := r.Get("db")
+	if r.Get("db") != db {
+		t.Error("Get returned a different logger for the same name")
+	}
+
+	db.Info("connected")
+	if !strings.Contains(buf.String(), "logger=db") {
+		t.Errorf("output %q is missing logger=db", buf)
+	}
+}
+
+func TestRegistryLevels(t *testing.T) {
+	var level slog.LevelVar
+	level.Set(slog.LevelWarn)
+	parent, _ := newBufferLogger(WithLevel(&level))
+	r := NewRegistry(parent)
+	ctx := context.Background()
+
+	db, http := r.Get("db"), r.Get("http")
+	if db.Enabled(ctx, slog.LevelInfo) {
+		t.Error("db enables INFO below the inherited WARN level")
+	}
+
+	// Lowering the parent level is inherited until a level is configured
+	level.Set(slog.LevelInfo)
+	if !db.Enabled(ctx, slog.LevelInfo) {
+		t.Error("db does not follow the parent level")
+	}
+
+	r.Configure("db", slog.LevelDebug)
+	if !db.Enabled(ctx, slog.LevelDebug) {
+		t.Error("db does not enable DEBUG after Configure")
+	}
+	if http.Enabled(ctx, slog.LevelDebug) {
+		t.Error("Configure of db changed the level of http")
+	}
+
+	// Configuring before the first Get applies to the logger once created
+	r.Configure("cache", slog.LevelError)
+	if r.Get("cache").Enabled(ctx, slog.LevelWarn) {
+		t.Error("cache enables WARN despite being configured to ERROR")
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	parent, _ := newBufferLogger()
+	r := NewRegistry(parent)
+
+	var wg sync.WaitGroup
+	loggers := make([]*Logger, 16)
+	for i := range loggers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			loggers[i] = r.Get("shared")
+			r.Configure("shared", slog.LevelDebug)
+			loggers[i].Enabled(context.Background(), slog.LevelDebug)
+		}()
+	}
+	wg.Wait()
+
+	for _, l := range loggers {
+		if l != loggers[0] {
+			t.Fatal("concurrent Get calls returned different loggers")
+		}
+	}
+}
+
+func TestGetLogger(t *testing.T) {
+	if GetLogger("pkg-test") != GetLogger("pkg-test") {
+		t.Error("GetLogger returned different loggers for the same name")
+	}
+}
@@ -0,0 +1,227 @@
+package sloglog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RotationSchedule controls how often file logging starts a new file
+type RotationSchedule int
+
+const (
+	// RotateDaily starts a new file every day, named like 2006-01-02.log (default)
+	RotateDaily RotationSchedule = iota
+	// RotateHourly starts a new file every hour, named like 2006-01-02_15.log
+	RotateHourly
+	// RotateWeekly starts a new file every ISO week, named like 2006-W01.log
+	RotateWeekly
+	// RotateMonthly starts a new file every month, named like 2006-01.log
+	RotateMonthly
+)
+
+// FileLoggerOption configures file logging enabled by EnableFileLogging
+type FileLoggerOption func(*FileLoggerOptions)
+
+// WithRotationSchedule sets how often a new log file is started
+func WithRotationSchedule(s RotationSchedule) FileLoggerOption {
+	return func(o *FileLoggerOptions) {
+		o.Schedule = s
+	}
+}
+
+// WithMaxSize starts a new file with an index suffix, like 2006-01-02.1.log, once the current
+// file has reached size bytes
+func WithMaxSize(size int64) FileLoggerOption {
+	return func(o *FileLoggerOptions) {
+		o.MaxSize = size
+	}
+}
+
+// WithCompression gzips rotated log files in the background, replacing 2006-01-02.log with
+// 2006-01-02.log.gz
+func WithCompression(enabled bool) FileLoggerOption {
+	return func(o *FileLoggerOptions) {
+		o.Compress = enabled
+	}
+}
+
+// WithIndexFile sets the name of the archive index kept in the log directory
+func WithIndexFile(name string) FileLoggerOption {
+	return func(o *FileLoggerOptions) {
+		o.IndexFile = name
+	}
+}
+
+// layout returns the Go time layout of the schedule's rotation key, or "" for RotateWeekly,
+// whose ISO week number cannot be expressed as a layout
+func (s RotationSchedule) layout() string {
+	switch s {
+	case RotateHourly:
+		return "2006-01-02_15"
+	case RotateWeekly:
+		return ""
+	case RotateMonthly:
+		return "2006-01"
+	default:
+		return "2006-01-02"
+	}
+}
+
+// key returns the rotation key of t; a new file is started whenever it changes
+func (s RotationSchedule) key(t time.Time) string {
+	if s == RotateWeekly {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	}
+	return t.Format(s.layout())
+}
+
+// matches reports whether base is a rotation key produced by the schedule
+func (s RotationSchedule) matches(base string) bool {
+	if s == RotateWeekly {
+		var year, week int
+		if _, err := fmt.Sscanf(base, "%04d-W%02d", &year, &week); err != nil {
+			return false
+		}
+		return base == fmt.Sprintf("%04d-W%02d", year, week)
+	}
+	_, err := time.Parse(s.layout(), base)
+	return err == nil
+}
+
+// logFileName returns the name, without extension, of the log file for t
+func (opts FileLoggerOptions) logFileName(t time.Time) string {
+	if opts.FilenameTemplate != "" {
+		return t.Format(opts.FilenameTemplate)
+	}
+	return opts.Schedule.key(t)
+}
+
+// recheckTime returns the time from which the log file name and rotation key for t may differ:
+// the start of the next period of the schedule, or the next second for a FilenameTemplate,
+// whose fields cannot be told apart cheaply
+func (opts FileLoggerOptions) recheckTime(t time.Time) time.Time {
+	next := opts.Schedule.start(t)
+	switch opts.Schedule {
+	case RotateHourly:
+		next = next.Add(time.Hour)
+	case RotateWeekly:
+		next = next.AddDate(0, 0, 7)
+	case RotateMonthly:
+		next = next.AddDate(0, 1, 0)
+	default:
+		next = next.AddDate(0, 0, 1)
+	}
+	if opts.FilenameTemplate != "" {
+		if second := t.Truncate(time.Second).Add(time.Second); second.Before(next) {
+			next = second
+		}
+	}
+	return next
+}
+
+// seqFileName returns the file name of the log file named name with index suffix seq
+func seqFileName(name string, seq int) string {
+	if seq == 0 {
+		return name + ".log"
+	}
+	return fmt.Sprintf("%s.%d.log", name, seq)
+}
+
+// lastSeq returns the highest index suffix of an existing file named name, 0 if there is none
+func (opts FileLoggerOptions) lastSeq(name string) int {
+	seq := 0
+	for opts.seqExists(name, seq+1) {
+		seq++
+	}
+	return seq
+}
+
+// seqExists reports whether the file named name with index suffix seq exists, compressed or not
+func (opts FileLoggerOptions) seqExists(name string, seq int) bool {
+	path := filepath.Join(opts.Dir, seqFileName(name, seq))
+	if _, err := os.Stat(path); err == nil {
+		return true
+	}
+	_, err := os.Stat(path + ".gz")
+	return err == nil
+}
+
+// freeSeq returns the first index suffix from seq on whose file can be appended to: it has not
+// been compressed and is below MaxSize
+func (opts FileLoggerOptions) freeSeq(name string, seq int) int {
+	for ; ; seq++ {
+		path := filepath.Join(opts.Dir, seqFileName(name, seq))
+		if _, err := os.Stat(path + ".gz"); err == nil {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && opts.MaxSize > 0 && info.Size() >= opts.MaxSize {
+			continue
+		}
+		return seq
+	}
+}
+
+// isLogFile reports whether name matches the naming pattern of log files produced by opts,
+// including index suffixes and compressed files
+func (opts FileLoggerOptions) isLogFile(name string) bool {
+	base, ok := strings.CutSuffix(strings.TrimSuffix(name, ".gz"), ".log")
+	if !ok {
+		return false
+	}
+	if opts.isLogName(base) {
+		return true
+	}
+	if i := strings.LastIndexByte(base, '.'); i >= 0 {
+		seq, err := strconv.Atoi(base[i+1:])
+		return err == nil && seq > 0 && opts.isLogName(base[:i])
+	}
+	return false
+}
+
+// isLogName reports whether base is a file name rendered by opts, without suffixes
+func (opts FileLoggerOptions) isLogName(base string) bool {
+	if opts.FilenameTemplate != "" {
+		_, err := time.Parse(opts.FilenameTemplate, base)
+		return err == nil
+	}
+	return opts.Schedule.matches(base)
+}
+
+// compressLogFile gzips the log file at path to path.gz and removes the original
+func compressLogFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to compress log file: %w", err)
+	}
+	defer src.Close()
+
+	tmp := path + ".gz.tmp"
+	dst, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to compress log file: %w", err)
+	}
+
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to compress log file %s: %w", path, err)
+	}
+	return os.Remove(path)
+}
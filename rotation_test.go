@@ -0,0 +1,267 @@
+package sloglog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testClock is a manually advanced clock for file loggers
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+// newTestFileLogger creates an enabled file logger in a temporary directory that reads the
+// time from clock, closing it when the test finishes
+func newTestFileLogger(t *testing.T, opts FileLoggerOptions, clock *testClock) *FileLogger {
+	t.Helper()
+	if opts.Dir == "" {
+		opts.Dir = t.TempDir()
+	}
+	fl := newFileLogger(opts)
+	fl.now = clock.Now
+	fl.enable()
+	t.Cleanup(func() { fl.Close() })
+	return fl
+}
+
+// logFileNames returns the sorted names of the log files in dir
+func logFileNames(t *testing.T, dir string) []string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestFilenameTemplate(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 14, 9, 59, 0, 0, time.UTC)}
+	fl := newTestFileLogger(t, FileLoggerOptions{FilenameTemplate: "payments_2006-01-02_15"}, clock)
+
+	fl.writeToFile("before the hour")
+	clock.now = clock.now.Add(30 * time.Second)
+	fl.writeToFile("still before the hour")
+	clock.now = clock.now.Add(time.Minute)
+	fl.writeToFile("after the hour")
+
+	want := []string{"payments_2026-03-14_09.log", "payments_2026-03-14_10.log"}
+	if got := logFileNames(t, fl.opts.Dir); !slices.Equal(got, want) {
+		t.Fatalf("log files = %v, want %v", got, want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(fl.opts.Dir, want[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "after the hour\n" {
+		t.Errorf("%s = %q, want only the record after the hour", want[1], data)
+	}
+}
+
+func TestDefaultFilename(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)}
+	fl := newTestFileLogger(t, FileLoggerOptions{}, clock)
+
+	fl.writeToFile("record")
+
+	if got, want := logFileNames(t, fl.opts.Dir), []string{"2026-03-14.log"}; !slices.Equal(got, want) {
+		t.Errorf("log files = %v, want %v", got, want)
+	}
+}
+
+func TestFilenameTemplateCoarserThanSchedule(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)}
+	opts := FileLoggerOptions{Dir: t.TempDir(), Schedule: RotateHourly, FilenameTemplate: "app_2006-01-02"}
+	fl := newTestFileLogger(t, opts, clock)
+
+	for _, msg := range []string{"09:30", "10:30", "11:30"} {
+		fl.writeToFile(msg)
+		clock.now = clock.now.Add(time.Hour)
+	}
+
+	want := []string{"app_2026-03-14.1.log", "app_2026-03-14.2.log", "app_2026-03-14.log"}
+	if got := logFileNames(t, opts.Dir); !slices.Equal(got, want) {
+		t.Fatalf("log files = %v, want one file per hour %v", got, want)
+	}
+
+	// After a restart within the last period, writing continues in the newest file
+	fl.Close()
+	clock.now = time.Date(2026, 3, 14, 11, 45, 0, 0, time.UTC)
+	restarted := newTestFileLogger(t, opts, clock)
+	restarted.writeToFile("11:45")
+
+	data, err := os.ReadFile(filepath.Join(opts.Dir, "app_2026-03-14.2.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "11:30\n11:45\n" {
+		t.Errorf("app_2026-03-14.2.log = %q, want the records of the last hour", data)
+	}
+	if got := logFileNames(t, opts.Dir); !slices.Equal(got, want) {
+		t.Errorf("log files after restart = %v, want %v", got, want)
+	}
+}
+
+func TestRotationSchedules(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule RotationSchedule
+		start    time.Time
+		step     time.Duration // advances the clock across the boundary
+		want     []string
+	}{
+		{
+			name:     "hour boundary",
+			schedule: RotateHourly,
+			start:    time.Date(2026, 3, 14, 9, 59, 30, 0, time.UTC),
+			step:     time.Minute,
+			want:     []string{"2026-03-14_09.log", "2026-03-14_10.log"},
+		},
+		{
+			name:     "week boundary",
+			schedule: RotateWeekly,
+			start:    time.Date(2026, 3, 15, 23, 30, 0, 0, time.UTC), // Sunday of ISO week 11
+			step:     time.Hour,
+			want:     []string{"2026-W11.log", "2026-W12.log"},
+		},
+		{
+			name:     "ISO year boundary",
+			schedule: RotateWeekly,
+			start:    time.Date(2026, 12, 27, 12, 0, 0, 0, time.UTC), // Sunday of week 52
+			step:     24 * time.Hour,
+			want:     []string{"2026-W52.log", "2026-W53.log"},
+		},
+		{
+			name:     "month boundary",
+			schedule: RotateMonthly,
+			start:    time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC),
+			step:     2 * time.Hour,
+			want:     []string{"2026-03.log", "2026-04.log"},
+		},
+		{
+			name:     "same day",
+			schedule: RotateDaily,
+			start:    time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC),
+			step:     10 * time.Hour,
+			want:     []string{"2026-03-14.log"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &testClock{now: tt.start}
+			fl := newTestFileLogger(t, FileLoggerOptions{Schedule: tt.schedule}, clock)
+
+			fl.writeToFile("before")
+			clock.now = clock.now.Add(tt.step)
+			fl.writeToFile("after")
+
+			if got := logFileNames(t, fl.opts.Dir); !slices.Equal(got, tt.want) {
+				t.Errorf("log files = %v, want %v", got, tt.want)
+			}
+			for _, name := range tt.want {
+				if !fl.opts.isLogFile(name) {
+					t.Errorf("%s is not recognized as a log file of the schedule", name)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxSize(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)}
+	fl := newTestFileLogger(t, FileLoggerOptions{MaxSize: 10}, clock)
+
+	fl.writeToFile("0123456789") // reaches the limit
+	fl.writeToFile("second")
+	fl.writeToFile("third") // the file is below the limit before the write
+	fl.writeToFile("fourth")
+
+	want := []string{"2026-03-14.1.log", "2026-03-14.2.log", "2026-03-14.log"}
+	if got := logFileNames(t, fl.opts.Dir); !slices.Equal(got, want) {
+		t.Fatalf("log files = %v, want %v", got, want)
+	}
+	data, err := os.ReadFile(filepath.Join(fl.opts.Dir, "2026-03-14.1.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "second\nthird\n" {
+		t.Errorf("2026-03-14.1.log = %q, want the records after the limit", data)
+	}
+	if !fl.opts.isLogFile("2026-03-14.2.log") {
+		t.Error("suffixed file is not recognized as a log file")
+	}
+}
+
+func TestMaxSizeExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "2026-03-14.log"), []byte("0123456789\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := &testClock{now: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)}
+	fl := newTestFileLogger(t, FileLoggerOptions{Dir: dir, MaxSize: 10}, clock)
+	fl.writeToFile("after restart")
+
+	data, err := os.ReadFile(filepath.Join(dir, "2026-03-14.1.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "after restart\n" {
+		t.Errorf("2026-03-14.1.log = %q, want the record written after the restart", data)
+	}
+}
+
+func TestCompress(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 14, 23, 59, 0, 0, time.UTC)}
+	fl := newTestFileLogger(t, FileLoggerOptions{Compress: true}, clock)
+
+	fl.writeToFile("yesterday")
+	clock.now = clock.now.Add(time.Hour)
+	fl.writeToFile("today")
+	fl.background.Wait()
+
+	if got, want := logFileNames(t, fl.opts.Dir), []string{"2026-03-15.log"}; !slices.Equal(got, want) {
+		t.Errorf("uncompressed log files = %v, want %v", got, want)
+	}
+
+	path, err := fl.LookupFile(time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "2026-03-14.log.gz" {
+		t.Fatalf("LookupFile = %s, want the compressed file", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "yesterday\n" {
+		t.Errorf("compressed contents = %q, want %q", data, "yesterday\n")
+	}
+	if !fl.opts.isLogFile(filepath.Base(path)) {
+		t.Error("compressed file is not recognized as a log file")
+	}
+}
@@ -1,205 +1,962 @@
 package sloglog
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/valyala/fasthttp"
+	"golang.org/x/term"
 )
 
 // Logger wraps slog.Logger to provide additional functionality
 type Logger struct {
 	logger    *slog.Logger
 	addSource bool
+	attrs     [][]slog.Attr // pre-set attributes by the number of groups open when added, mirrored for file output
+	groups    []string      // open groups, mirrored for file output
+	// ungrouped is the handler before the first open group, nil while no group is open
+	ungrouped slog.Handler
+	// contextKeys are extracted from the context of every record alongside the trace ID
+	contextKeys []string
+	extractors  []ContextExtractor
+	beforeHooks []Hook
+	afterHooks  []Hook
+	errorKey    string
+	traceIDKey  string // attribute key of trace IDs from the context
+	callerSkip  int    // extra stack frames to skip for source attribution
+	goroutineID bool
+	exitFunc    func(int)
+	panicFunc   func(string)
+	auxFiles    []auxFile
+	redactor    *redactor // nil when no keys are redacted
+	timeFormat  string    // timestamp layout of file entries, "" for the default
+	prefix      string    // prepended to messages as "prefix: "
+	treeStyle   TreeStyle
+
+	// contextDeadline adds the remaining time of context deadlines within deadlineThreshold
+	contextDeadline   bool
+	deadlineThreshold time.Duration
 }
 
 // FileLogger manages file logging with daily rotation
 type FileLogger struct {
-	mu      sync.RWMutex
+	mu      sync.Mutex
 	file    *os.File
-	dir     string
-	date    string
-	enabled bool
+	buf     *bufio.Writer // wraps file when BufferSize > 0
+	stop    chan struct{} // stops the background flusher
+	opts    FileLoggerOptions
+	name    string    // rendered filename of the current file
+	period  string    // rotation key of the current file
+	seq     int       // index suffix of the current file, 0 for none
+	size    int64     // size of the current file including buffered entries
+	recheck time.Time // time from which the filename and rotation key must be rendered again
+	line    []byte    // reused to append the newline to entries written without buffer
+	index   []IndexEntry
+	indexed sync.Once // loads the index from disk when the logger is first enabled
+	enabled atomic.Bool
+	now     func() time.Time // clock injected by tests, nil for the real one, see clock
+	anchor  time.Time        // last reading of the real clock, guarded by mu
+
+	// Background writer used when AsyncBufferSize > 0, guarded by asyncMu
+	asyncMu   sync.RWMutex
+	async     chan asyncEntry
+	asyncDone chan struct{}
+
+	// Background compression and retention of rotated files, see housekeep. Close waits for
+	// background, housekept is closed by the last call of housekeep once it is done
+	background sync.WaitGroup
+	housekept  chan struct{}
+
+	// Statistics reported by Stats. records and lastWrite are guarded by mu, which every
+	// write holds anyway
+	records   int64 // records written since the last rotation
+	lastWrite time.Time
+	rotations atomic.Int64
+	dropped   atomic.Int64
 }
 
-// Global file logger instance
-var fileLogger *FileLogger
+// FileLoggerOptions configures file logging
+type FileLoggerOptions struct {
+	// Dir is the directory where log files are stored (default: LOG_DIR_PATH or {PROJECT_DIR}/external/logs)
+	Dir string
 
-// Package-level loggers
+	// Schedule controls how often a new log file is started (default: RotateDaily)
+	Schedule RotationSchedule
+
+	// FilenameTemplate is a Go time layout used to name log files, e.g. "payments_2006-01-02".
+	// A new file is also opened whenever the rendered name changes. When the template omits
+	// the time fields of Schedule, files of later periods get an index suffix such as
+	// payments_2006-01-02.1.log (default: derived from Schedule, "2006-01-02" for daily rotation)
+	FilenameTemplate string
+
+	// MaxAge is the age after which rotated log files are deleted (default: 0, keep forever)
+	MaxAge time.Duration
+
+	// MaxCount is the number of most recent log files to keep (default: 0, keep all)
+	MaxCount int
+
+	// MaxSize is the size in bytes after which a new file is started within the same period,
+	// named with an index suffix like 2006-01-02.1.log (default: 0, no limit)
+	MaxSize int64
+
+	// Compress gzips log files in the background once they have been rotated
+	Compress bool
+
+	// IndexFile is the name of the archive index kept in Dir. File loggers sharing a directory
+	// need distinct index files (default: index.json, <tag>_index.json for WithAuxFile)
+	IndexFile string
+
+	// BufferSize is the capacity in bytes of the write buffer (default: 0, unbuffered)
+	BufferSize int
+
+	// FlushInterval is how often the write buffer is flushed (default: 1 second when buffered)
+	FlushInterval time.Duration
+
+	// AsyncBufferSize is the number of entries queued for a background writer (default: 0, synchronous)
+	AsyncBufferSize int
+
+	// DropOnFull drops entries instead of blocking when the async queue is full
+	DropOnFull bool
+}
+
+// defaultFlushInterval is used when BufferSize is set without FlushInterval
+const defaultFlushInterval = time.Second
+
+// Global file logger instance, set by init and replaced atomically when file logging is reconfigured
+var fileLogger atomic.Pointer[FileLogger]
+
+// Package-level loggers, stored atomically so they can be swapped while other goroutines log
 var (
-	defaultLogger *Logger
-	Min           *Logger
+	defaultLogger atomic.Pointer[Logger]
+	minLogger     atomic.Pointer[Logger]
 )
 
-// TraceIDKey is the key used to store trace IDs in context
-const TraceIDKey = "trace_id"
+// Min is the minimal logger without source tracking, set by InitLogger. SetMinLogger does not
+// update it.
+//
+// Deprecated: reading Min is not safe while InitLogger runs concurrently; use MinLogger instead.
+var Min *Logger
+
+// Custom levels above slog.LevelError
+const (
+	LevelPanic slog.Level = 10
+	LevelFatal slog.Level = 12
+)
+
+// Names of the request IDs, used as attribute keys and as fasthttp user value keys.
+//
+// Deprecated: as context.Context keys the strings may collide with values stored by other
+// packages; use TraceIDContextKey, SpanIDContextKey and CorrelationIDContextKey with
+// context.WithValue instead. Values stored under the strings are read only after
+// SetLegacyStringKeys(true)
+const (
+	// TraceIDKey is the key used to store trace IDs
+	TraceIDKey = "trace_id"
+	// SpanIDKey is the key used to store span IDs identifying the local operation
+	SpanIDKey = "span_id"
+	// CorrelationIDKey is the key used to store IDs shared by an entire business transaction
+	CorrelationIDKey = "correlation_id"
+)
+
+// Unexported key types, so that no other package can create colliding context keys
+type (
+	traceIDKeyType       struct{}
+	spanIDKeyType        struct{}
+	correlationIDKeyType struct{}
+)
+
+// Keys used to store request IDs in context.Context
+var (
+	// TraceIDContextKey is the context key of trace IDs
+	TraceIDContextKey = traceIDKeyType{}
+	// SpanIDContextKey is the context key of span IDs
+	SpanIDContextKey = spanIDKeyType{}
+	// CorrelationIDContextKey is the context key of correlation IDs
+	CorrelationIDContextKey = correlationIDKeyType{}
+)
+
+// requestIDKeys are extracted from the context of every record
+var requestIDKeys = []string{TraceIDKey, SpanIDKey, CorrelationIDKey}
+
+// requestIDContextKeys maps the names of the request IDs to their context.Context keys
+var requestIDContextKeys = map[string]any{
+	TraceIDKey:       TraceIDContextKey,
+	SpanIDKey:        SpanIDContextKey,
+	CorrelationIDKey: CorrelationIDContextKey,
+}
+
+// legacyStringKeys enables reading request IDs stored under the deprecated string keys
+var legacyStringKeys atomic.Bool
+
+// SetLegacyStringKeys makes GetTraceID, GetSpanID, GetCorrelationID and context logging also read
+// request IDs stored in context.Context under the deprecated string keys, e.g. TraceIDKey, when the
+// typed key holds none. It eases migrating code that still stores the strings, but then matches
+// "trace_id" values stored by any other package as well (default: disabled)
+func SetLegacyStringKeys(enabled bool) {
+	legacyStringKeys.Store(enabled)
+}
+
+// requestIDContextKey returns the context.Context key of the request ID name, or name itself for other keys
+func requestIDContextKey(name string) any {
+	if key, ok := requestIDContextKeys[name]; ok {
+		return key
+	}
+	return name
+}
 
 // TraceIDToFHCtx adds a new trace ID to fasthttp context
 func TraceIDToFHCtx(ctx *fasthttp.RequestCtx) {
 	ctx.SetUserValue(TraceIDKey, uuid.New().String())
 }
 
+// RequestIDsToFHCtx adds new trace, span and correlation IDs to fasthttp context
+func RequestIDsToFHCtx(ctx *fasthttp.RequestCtx) {
+	for _, key := range requestIDKeys {
+		ctx.SetUserValue(key, uuid.New().String())
+	}
+}
+
 // CtxWithTraceID creates a new context with timeout and trace ID
 func CtxWithTraceID(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithTimeout(parent, timeout)
-	return context.WithValue(ctx, TraceIDKey, uuid.New().String()), cancel
+	return context.WithValue(ctx, TraceIDContextKey, uuid.New().String()), cancel
+}
+
+// CtxWithIDs creates a new context with timeout and new trace, span and correlation IDs
+func CtxWithIDs(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	for _, key := range requestIDKeys {
+		ctx = context.WithValue(ctx, requestIDContextKey(key), uuid.New().String())
+	}
+	return ctx, cancel
+}
+
+// CtxWithSpan creates a new context with timeout for a new span of the trace of parent. The trace ID
+// is copied from parent, or generated if parent has none, and a new span ID is generated
+func CtxWithSpan(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	traceID := GetTraceID(parent)
+	if traceID == "" {
+		traceID = uuid.New().String()
+	}
+
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	ctx = context.WithValue(ctx, TraceIDContextKey, traceID)
+	return context.WithValue(ctx, SpanIDContextKey, uuid.New().String()), cancel
 }
 
 // GetTraceID extracts trace ID from context
 func GetTraceID(ctx any) string {
+	return getContextString(ctx, TraceIDKey)
+}
+
+// GetSpanID extracts span ID from context
+func GetSpanID(ctx any) string {
+	return getContextString(ctx, SpanIDKey)
+}
+
+// GetCorrelationID extracts correlation ID from context
+func GetCorrelationID(ctx any) string {
+	return getContextString(ctx, CorrelationIDKey)
+}
+
+// getContextString extracts a string or fmt.Stringer value stored under key
+// from a fasthttp.RequestCtx or context.Context
+func getContextString(ctx any, key string) string {
 	if ctx == nil {
 		return ""
 	}
 
-	// Try to get trace ID from fasthttp.RequestCtx
+	var v any
 	if requestCtx, ok := ctx.(*fasthttp.RequestCtx); ok {
-		if v := requestCtx.UserValue(TraceIDKey); v != nil {
-			return v.(string)
+		// Try to get the value from fasthttp.RequestCtx
+		v = requestCtx.UserValue(key)
+	} else if stdCtx, ok := ctx.(context.Context); ok {
+		// Try to get the value from context.Context, falling back to the deprecated string key
+		// only if enabled, since any package may store values under the string
+		v = stdCtx.Value(requestIDContextKey(key))
+		if v == nil && legacyStringKeys.Load() {
+			v = stdCtx.Value(key)
 		}
-		return ""
 	}
 
-	// Try to get trace ID from context.Context
-	if stdCtx, ok := ctx.(context.Context); ok {
-		if v := stdCtx.Value(TraceIDKey); v != nil {
-			if traceID, ok := v.(string); ok {
-				return traceID
-			}
-		}
+	switch v := v.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
 	}
 
 	return ""
 }
 
-// log implements the core logging functionality
+// log implements the core logging functionality, attributing the record to the caller
+// callerSkip frames above log
 func (l *Logger) log(ctx context.Context, callerSkip int, level slog.Level, msg string, args ...any) {
-	attrs := make([]slog.Attr, 0, len(args)+1)
+	// Drop disabled records before any work, so that no output receives them
+	if !l.Enabled(ctx, level) {
+		return
+	}
 
-	// Add trace ID if available
+	l.handle(ctx, l.source(callerSkip), level, msg, args, nil)
+}
+
+// LogAttrs logs at level with context, accepting only attributes. As with
+// slog.Logger.LogAttrs, the attributes are not converted to ...any, so that a call at a
+// disabled level does not allocate, e.g. for LazyAttr values on a hot path
+func (l *Logger) LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	l.handle(ctx, l.source(1), level, msg, nil, attrs)
+}
+
+// source returns the source of the caller skip frames above the caller of source, or ""
+// when source tracking is disabled
+func (l *Logger) source(skip int) string {
+	if !l.addSource {
+		return ""
+	}
+	return callerSource(skip + 1 + l.callerSkip)
+}
+
+// logWithSource logs a record attributed to source, for records logged on behalf of a call site
+// that is no longer on the stack, such as the installation of a middleware
+func (l *Logger) logWithSource(ctx context.Context, source string, level slog.Level, msg string, args ...any) {
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	if !l.addSource {
+		source = ""
+	}
+	l.handle(ctx, source, level, msg, args, nil)
+}
+
+// callerSource formats the file and line of the caller skip frames above the caller of
+// callerSource as "[file:line]", or returns "" if it cannot be determined
+func callerSource(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("[%s:%d]", file, line)
+}
+
+// handle builds the record of an enabled level from args in key-value form followed by
+// callAttrs and writes it to all outputs. An empty source is omitted
+func (l *Logger) handle(ctx context.Context, source string, level slog.Level, msg string, args []any, callAttrs []slog.Attr) {
+	if l.prefix != "" {
+		msg = l.prefix + ": " + msg
+	}
+
+	// Leave room for the goroutine ID and the source
+	attrs := make([]slog.Attr, 0, len(args)+len(callAttrs)+2)
+
+	// Add request IDs if available
 	if ctx != nil {
-		traceID := GetTraceID(ctx)
-		if traceID != "" {
-			attrs = append(attrs, slog.String("trace_id", traceID))
+		for _, key := range requestIDKeys {
+			if id := getContextString(ctx, key); id != "" {
+				if key == TraceIDKey && l.traceIDKey != "" {
+					key = l.traceIDKey
+				}
+				attrs = append(attrs, slog.String(key, id))
+			}
 		}
+
+		// Add registered context values
+		for _, key := range l.contextKeys {
+			if v := getContextString(ctx, key); v != "" {
+				attrs = append(attrs, slog.String(key, v))
+			}
+		}
+
+		// Add the remaining time of a close deadline
+		if l.contextDeadline {
+			if deadline, ok := ctx.Deadline(); ok {
+				remaining := time.Until(deadline)
+				if l.deadlineThreshold == 0 || remaining <= l.deadlineThreshold {
+					attrs = append(attrs, slog.Int64("deadline_remaining_ms", remaining.Milliseconds()))
+				}
+			}
+		}
+
+		// Add attributes from context extractors, explicit values take priority. Trace IDs
+		// are renamed like the one from the context
+		for _, extract := range l.extractors {
+			for _, attr := range extract(ctx) {
+				if attr.Key == TraceIDKey && l.traceIDKey != "" {
+					attr.Key = l.traceIDKey
+				}
+				if !hasAttrKey(attrs, attr.Key) {
+					attrs = append(attrs, attr)
+				}
+			}
+		}
+	}
+
+	// Add goroutine ID if enabled
+	if l.goroutineID {
+		attrs = append(attrs, slog.Uint64("goroutine_id", goroutineID()))
 	}
 
+	// The attributes so far stay at the top level when groups are open
+	topLevel := attrs[:len(attrs):len(attrs)]
+
 	// Add source information if enabled
-	if l.addSource {
-		_, file, line, ok := runtime.Caller(callerSkip)
-		if ok {
-			attrs = append(attrs, slog.String("source", fmt.Sprintf("[%s:%d]", file, line)))
-		}
+	if source != "" {
+		attrs = append(attrs, slog.String("source", source))
 	}
 
-	// Add additional attributes
-	for i := range args {
-		if attr, ok := args[i].(slog.Attr); ok {
-			attrs = append(attrs, attr)
+	// Add additional attributes in slog.Attr or key-value form. Resolving the values evaluates
+	// LazyAttr values now that the level is known to be enabled
+	attrs = appendKeyValueArgs(attrs, args...)
+	for _, attr := range callAttrs {
+		if attr.Value.Kind() == slog.KindLogValuer {
+			attr.Value = attr.Value.Resolve()
 		}
+		attrs = append(attrs, attr)
 	}
 
 	record := slog.NewRecord(time.Now(), level, msg, 0)
 	record.AddAttrs(attrs...)
 
-	// Write to stdout/stderr
-	l.logger.Handler().Handle(ctx, record)
+	// Run before hooks, which may transform or drop the record
+	for _, hook := range l.beforeHooks {
+		record = hook(record)
+		if record.Time.IsZero() {
+			return
+		}
+	}
+
+	// Mask sensitive attributes on a copy of the record
+	if l.redactor != nil {
+		record = l.redactor.record(record)
+	}
+
+	top, grouped := l.splitTopLevel(record, topLevel)
+
+	// Route tagged records to their aux files
+	l.writeAuxFiles(top, grouped)
+
+	// Write to stdout/stderr. The top-level attributes are added to the handler before its
+	// groups are opened
+	handler := l.logger.Handler()
+	if len(top) > 0 {
+		handler = l.withGroups(l.ungrouped.WithAttrs(top))
+	}
+	handler.Handle(ctx, grouped)
 
 	// Write to file if enabled
-	if fileLogger != nil && fileLogger.enabled {
-		logEntry := l.formatLogEntry(record)
-		fileLogger.writeToFile(logEntry)
+	if fl := fileLogger.Load(); fl.enabled.Load() {
+		logEntry := l.formatLogEntry(top, grouped)
+		fl.writeToFile(logEntry)
+	}
+
+	// Run after hooks with the record as written
+	for _, hook := range l.afterHooks {
+		hook(record)
+	}
+}
+
+// splitTopLevel separates the attributes of record whose keys are those of topLevel, taken from
+// the context before the hooks ran, from the others when groups are open. Otherwise, or when
+// there are none, it returns no attributes and record itself
+func (l *Logger) splitTopLevel(record slog.Record, topLevel []slog.Attr) ([]slog.Attr, slog.Record) {
+	if l.ungrouped == nil || len(topLevel) == 0 {
+		return nil, record
+	}
+
+	var top, rest []slog.Attr
+	record.Attrs(func(a slog.Attr) bool {
+		if hasAttrKey(topLevel, a.Key) {
+			top = append(top, a)
+		} else {
+			rest = append(rest, a)
+		}
+		return true
+	})
+	if len(top) == 0 {
+		return nil, record
+	}
+
+	grouped := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	grouped.AddAttrs(rest...)
+	return top, grouped
+}
+
+// withGroups opens the groups of l on h, which holds the attributes added outside of any group,
+// adding the attributes of each group after it is opened
+func (l *Logger) withGroups(h slog.Handler) slog.Handler {
+	for i, group := range l.groups {
+		h = h.WithGroup(group)
+		if i+1 < len(l.attrs) && len(l.attrs[i+1]) > 0 {
+			h = h.WithAttrs(l.attrs[i+1])
+		}
+	}
+	return h
+}
+
+// ContextExtractor derives attributes from the context of a record. Attributes whose key is
+// already present, e.g. a trace ID stored in the context, are skipped. An attribute keyed
+// trace_id is renamed by WithTraceIDKey
+type ContextExtractor func(ctx context.Context) []slog.Attr
+
+// hasAttrKey reports whether attrs contains an attribute with the given key
+func hasAttrKey(attrs []slog.Attr, key string) bool {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return true
+		}
 	}
+	return false
+}
+
+// Hook observes or transforms a record around the write in Logger.log.
+// A before hook that returns a record with a zero Time, such as slog.Record{}, drops the record.
+// The record returned by an after hook is ignored
+type Hook func(record slog.Record) slog.Record
+
+// Enabled reports whether the logger emits records at level. Records at disabled levels are
+// dropped by every output, including file logging
+func (l *Logger) Enabled(ctx context.Context, level slog.Level) bool {
+	return l.logger.Handler().Enabled(ctx, level)
 }
 
 // Debug logs at debug level without context
 func (l *Logger) Debug(msg string, args ...any) {
-	l.log(context.Background(), 3, slog.LevelDebug, msg, args...)
+	l.log(context.Background(), 2, slog.LevelDebug, msg, args...)
 }
 
 // Info logs at info level without context
 func (l *Logger) Info(msg string, args ...any) {
-	l.log(context.Background(), 3, slog.LevelInfo, msg, args...)
+	l.log(context.Background(), 2, slog.LevelInfo, msg, args...)
 }
 
 // Warn logs at warn level without context
 func (l *Logger) Warn(msg string, args ...any) {
-	l.log(context.Background(), 3, slog.LevelWarn, msg, args...)
+	l.log(context.Background(), 2, slog.LevelWarn, msg, args...)
 }
 
 // Error logs at error level without context
 func (l *Logger) Error(msg string, args ...any) {
-	l.log(context.Background(), 3, slog.LevelError, msg, args...)
+	l.log(context.Background(), 2, slog.LevelError, msg, args...)
 }
 
 // DebugCtx logs at debug level with context
 func (l *Logger) DebugCtx(ctx context.Context, msg string, args ...any) {
-	l.log(ctx, 3, slog.LevelDebug, msg, args...)
+	l.log(ctx, 2, slog.LevelDebug, msg, args...)
 }
 
 // InfoCtx logs at info level with context
 func (l *Logger) InfoCtx(ctx context.Context, msg string, args ...any) {
-	l.log(ctx, 3, slog.LevelInfo, msg, args...)
+	l.log(ctx, 2, slog.LevelInfo, msg, args...)
 }
 
 // WarnCtx logs at warn level with context
 func (l *Logger) WarnCtx(ctx context.Context, msg string, args ...any) {
-	l.log(ctx, 3, slog.LevelWarn, msg, args...)
+	l.log(ctx, 2, slog.LevelWarn, msg, args...)
 }
 
 // ErrorCtx logs at error level with context
 func (l *Logger) ErrorCtx(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, 2, slog.LevelError, msg, args...)
+}
+
+// With returns a child logger that includes the given attributes in every record
+func (l *Logger) With(attrs ...slog.Attr) *Logger {
+	if l.redactor != nil {
+		attrs = l.redactor.attrs(attrs)
+	}
+
+	args := make([]any, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+
+	child := l.clone()
+	child.logger = l.logger.With(args...)
+	depth := len(l.groups)
+	child.attrs = make([][]slog.Attr, max(len(l.attrs), depth+1))
+	copy(child.attrs, l.attrs)
+	current := child.attrs[depth]
+	child.attrs[depth] = append(current[:len(current):len(current)], attrs...)
+	return child
+}
+
+// Group returns a child logger that nests the attributes of its records under name, keeping
+// the configuration of l. Groups stack, and file output writes the keys as "name.key"
+func (l *Logger) Group(name string) *Logger {
+	if name == "" {
+		return l
+	}
+
+	child := l.clone()
+	child.logger = l.logger.WithGroup(name)
+	if l.ungrouped == nil {
+		child.ungrouped = l.logger.Handler()
+	}
+	child.groups = append(l.groups[:len(l.groups):len(l.groups)], name)
+	return child
+}
+
+// GroupFunc calls fn with a child logger that nests the attributes of its records under name
+func (l *Logger) GroupFunc(name string, fn func(lg *Logger)) {
+	fn(l.Group(name))
+}
+
+// DebugFunc logs at debug level without context, calling fn for the attributes only if the level is enabled
+func (l *Logger) DebugFunc(msg string, fn func() []slog.Attr) {
+	l.logFunc(context.Background(), slog.LevelDebug, msg, fn)
+}
+
+// InfoFunc logs at info level without context, calling fn for the attributes only if the level is enabled
+func (l *Logger) InfoFunc(msg string, fn func() []slog.Attr) {
+	l.logFunc(context.Background(), slog.LevelInfo, msg, fn)
+}
+
+// WarnFunc logs at warn level without context, calling fn for the attributes only if the level is enabled
+func (l *Logger) WarnFunc(msg string, fn func() []slog.Attr) {
+	l.logFunc(context.Background(), slog.LevelWarn, msg, fn)
+}
+
+// ErrorFunc logs at error level without context, calling fn for the attributes only if the level is enabled
+func (l *Logger) ErrorFunc(msg string, fn func() []slog.Attr) {
+	l.logFunc(context.Background(), slog.LevelError, msg, fn)
+}
+
+// DebugCtxFunc logs at debug level with context, calling fn for the attributes only if the level is enabled
+func (l *Logger) DebugCtxFunc(ctx context.Context, msg string, fn func() []slog.Attr) {
+	l.logFunc(ctx, slog.LevelDebug, msg, fn)
+}
+
+// InfoCtxFunc logs at info level with context, calling fn for the attributes only if the level is enabled
+func (l *Logger) InfoCtxFunc(ctx context.Context, msg string, fn func() []slog.Attr) {
+	l.logFunc(ctx, slog.LevelInfo, msg, fn)
+}
+
+// WarnCtxFunc logs at warn level with context, calling fn for the attributes only if the level is enabled
+func (l *Logger) WarnCtxFunc(ctx context.Context, msg string, fn func() []slog.Attr) {
+	l.logFunc(ctx, slog.LevelWarn, msg, fn)
+}
+
+// ErrorCtxFunc logs at error level with context, calling fn for the attributes only if the level is enabled
+func (l *Logger) ErrorCtxFunc(ctx context.Context, msg string, fn func() []slog.Attr) {
+	l.logFunc(ctx, slog.LevelError, msg, fn)
+}
+
+// logFunc checks that level is enabled before evaluating fn and logging its attributes
+func (l *Logger) logFunc(ctx context.Context, level slog.Level, msg string, fn func() []slog.Attr) {
+	if !l.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := fn()
+	args := make([]any, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+	l.log(ctx, 3, level, msg, args...)
+}
+
+// Fatal logs at fatal level without context and then exits with status 1
+func (l *Logger) Fatal(msg string, args ...any) {
+	l.logAndExit(context.Background(), msg, args...)
+}
+
+// FatalCtx logs at fatal level with context and then exits with status 1
+func (l *Logger) FatalCtx(ctx context.Context, msg string, args ...any) {
+	l.logAndExit(ctx, msg, args...)
+}
+
+// logAndExit logs at fatal level, flushes the files and exits with status 1
+func (l *Logger) logAndExit(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, 3, LevelFatal, msg, args...)
+	flushFileLogger()
+	l.flushAuxFiles()
+	l.exitFunc(1)
+}
+
+// flushFileLogger flushes buffered file entries, e.g. before the process exits
+func flushFileLogger() {
+	fileLogger.Load().Flush()
+}
+
+// Panic logs at panic level without context and then panics with msg
+func (l *Logger) Panic(msg string, args ...any) {
+	l.logAndPanic(context.Background(), msg, args...)
+}
+
+// PanicCtx logs at panic level with context and then panics with msg
+func (l *Logger) PanicCtx(ctx context.Context, msg string, args ...any) {
+	l.logAndPanic(ctx, msg, args...)
+}
+
+// logAndPanic logs at panic level and then panics with msg
+func (l *Logger) logAndPanic(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, 3, LevelPanic, msg, args...)
+	l.panicFunc(msg)
+}
+
+// WithError returns a child logger that includes err in every record, or l itself when err is nil.
+// Wrapped errors are included as described for ErrorCtxErr
+func (l *Logger) WithError(err error) *Logger {
+	if err == nil {
+		return l
+	}
+	return l.With(errorAttrs(l.errorKey, err)...)
+}
+
+// ErrorCtxErr logs at error level with context, attaching err as an attribute. When err wraps
+// other errors, their messages are attached level by level as a list under "<error key>.chain"
+func (l *Logger) ErrorCtxErr(ctx context.Context, msg string, err error, args ...any) {
+	l.errorCtxErr(ctx, msg, err, args...)
+}
+
+// errorCtxErr implements ErrorCtxErr for the method and the package-level function
+func (l *Logger) errorCtxErr(ctx context.Context, msg string, err error, args ...any) {
+	if err != nil {
+		args = args[:len(args):len(args)]
+		for _, attr := range errorAttrs(l.errorKey, err) {
+			args = append(args, attr)
+		}
+	}
 	l.log(ctx, 3, slog.LevelError, msg, args...)
 }
 
+// errorAttrs returns the message of err under key, followed by the messages of the errors it
+// wraps under key+".chain" if there are any
+func errorAttrs(key string, err error) []slog.Attr {
+	attrs := []slog.Attr{slog.String(key, err.Error())}
+	if chain := errorChain(err); len(chain) > 0 {
+		attrs = append(attrs, slog.Any(key+".chain", chain))
+	}
+	return attrs
+}
+
+// errorChain returns the messages of the errors wrapped by err, outermost first. The errors
+// of an Unwrap() []error, as returned by errors.Join, are walked depth-first in order
+func errorChain(err error) []string {
+	var chain []string
+	var walk func(err error)
+	walk = func(err error) {
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, wrapped := range multi.Unwrap() {
+				if wrapped != nil {
+					chain = append(chain, wrapped.Error())
+					walk(wrapped)
+				}
+			}
+			return
+		}
+		if wrapped := errors.Unwrap(err); wrapped != nil {
+			chain = append(chain, wrapped.Error())
+			walk(wrapped)
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// WithContextKeys returns a child logger that also extracts the values stored under keys
+// from the context of every record. Values must be a string or fmt.Stringer
+func (l *Logger) WithContextKeys(keys ...string) *Logger {
+	child := l.clone()
+	child.contextKeys = append(l.contextKeys[:len(l.contextKeys):len(l.contextKeys)], keys...)
+	return child
+}
+
+// WithHooks returns a child logger that runs before ahead of every write and after once the
+// record has been written. Either hook may be nil. Hooks added to a parent logger run first
+func (l *Logger) WithHooks(before, after Hook) *Logger {
+	child := l.clone()
+	if before != nil {
+		child.beforeHooks = append(l.beforeHooks[:len(l.beforeHooks):len(l.beforeHooks)], before)
+	}
+	if after != nil {
+		child.afterHooks = append(l.afterHooks[:len(l.afterHooks):len(l.afterHooks)], after)
+	}
+	return child
+}
+
+// WithCallerSkip returns a child logger that skips n additional stack frames when
+// attributing the source, for use by libraries that wrap Logger
+func (l *Logger) WithCallerSkip(n int) *Logger {
+	child := l.clone()
+	child.callerSkip += n
+	return child
+}
+
+// WithPrefix returns a child logger that prepends "prefix: " to every message.
+// Prefixes stack, so WithPrefix("db").WithPrefix("pool") prepends "db.pool: "
+func (l *Logger) WithPrefix(prefix string) *Logger {
+	child := l.clone()
+	if l.prefix == "" {
+		child.prefix = prefix
+	} else {
+		child.prefix = l.prefix + "." + prefix
+	}
+	return child
+}
+
+// clone returns a shallow copy of the logger
+func (l *Logger) clone() *Logger {
+	c := *l
+	return &c
+}
+
 // Package-level convenience functions that use the default logger
 
+// Enabled reports whether the default logger emits records at level
+func Enabled(ctx context.Context, level slog.Level) bool {
+	return defaultLogger.Load().Enabled(ctx, level)
+}
+
 // Debug logs at debug level without context
 func Debug(msg string, args ...any) {
-	defaultLogger.Debug(msg, args...)
+	defaultLogger.Load().log(context.Background(), 2, slog.LevelDebug, msg, args...)
 }
 
 // Info logs at info level without context
 func Info(msg string, args ...any) {
-	defaultLogger.Info(msg, args...)
+	defaultLogger.Load().log(context.Background(), 2, slog.LevelInfo, msg, args...)
 }
 
 // Warn logs at warn level without context
 func Warn(msg string, args ...any) {
-	defaultLogger.Warn(msg, args...)
+	defaultLogger.Load().log(context.Background(), 2, slog.LevelWarn, msg, args...)
 }
 
 // Error logs at error level without context
 func Error(msg string, args ...any) {
-	defaultLogger.Error(msg, args...)
+	defaultLogger.Load().log(context.Background(), 2, slog.LevelError, msg, args...)
 }
 
 // DebugCtx logs at debug level with context
 func DebugCtx(ctx context.Context, msg string, args ...any) {
-	defaultLogger.DebugCtx(ctx, msg, args...)
+	defaultLogger.Load().log(ctx, 2, slog.LevelDebug, msg, args...)
 }
 
 // InfoCtx logs at info level with context
 func InfoCtx(ctx context.Context, msg string, args ...any) {
-	defaultLogger.InfoCtx(ctx, msg, args...)
+	defaultLogger.Load().log(ctx, 2, slog.LevelInfo, msg, args...)
 }
 
 // WarnCtx logs at warn level with context
 func WarnCtx(ctx context.Context, msg string, args ...any) {
-	defaultLogger.WarnCtx(ctx, msg, args...)
+	defaultLogger.Load().log(ctx, 2, slog.LevelWarn, msg, args...)
 }
 
 // ErrorCtx logs at error level with context
 func ErrorCtx(ctx context.Context, msg string, args ...any) {
-	defaultLogger.ErrorCtx(ctx, msg, args...)
+	defaultLogger.Load().log(ctx, 2, slog.LevelError, msg, args...)
+}
+
+// DebugFunc logs at debug level without context, calling fn for the attributes only if the level is enabled
+func DebugFunc(msg string, fn func() []slog.Attr) {
+	defaultLogger.Load().logFunc(context.Background(), slog.LevelDebug, msg, fn)
+}
+
+// InfoFunc logs at info level without context, calling fn for the attributes only if the level is enabled
+func InfoFunc(msg string, fn func() []slog.Attr) {
+	defaultLogger.Load().logFunc(context.Background(), slog.LevelInfo, msg, fn)
+}
+
+// WarnFunc logs at warn level without context, calling fn for the attributes only if the level is enabled
+func WarnFunc(msg string, fn func() []slog.Attr) {
+	defaultLogger.Load().logFunc(context.Background(), slog.LevelWarn, msg, fn)
+}
+
+// ErrorFunc logs at error level without context, calling fn for the attributes only if the level is enabled
+func ErrorFunc(msg string, fn func() []slog.Attr) {
+	defaultLogger.Load().logFunc(context.Background(), slog.LevelError, msg, fn)
+}
+
+// DebugCtxFunc logs at debug level with context, calling fn for the attributes only if the level is enabled
+func DebugCtxFunc(ctx context.Context, msg string, fn func() []slog.Attr) {
+	defaultLogger.Load().logFunc(ctx, slog.LevelDebug, msg, fn)
+}
+
+// InfoCtxFunc logs at info level with context, calling fn for the attributes only if the level is enabled
+func InfoCtxFunc(ctx context.Context, msg string, fn func() []slog.Attr) {
+	defaultLogger.Load().logFunc(ctx, slog.LevelInfo, msg, fn)
+}
+
+// WarnCtxFunc logs at warn level with context, calling fn for the attributes only if the level is enabled
+func WarnCtxFunc(ctx context.Context, msg string, fn func() []slog.Attr) {
+	defaultLogger.Load().logFunc(ctx, slog.LevelWarn, msg, fn)
+}
+
+// ErrorCtxFunc logs at error level with context, calling fn for the attributes only if the level is enabled
+func ErrorCtxFunc(ctx context.Context, msg string, fn func() []slog.Attr) {
+	defaultLogger.Load().logFunc(ctx, slog.LevelError, msg, fn)
+}
+
+// Fatal logs at fatal level without context and then exits with status 1
+func Fatal(msg string, args ...any) {
+	defaultLogger.Load().logAndExit(context.Background(), msg, args...)
+}
+
+// FatalCtx logs at fatal level with context and then exits with status 1
+func FatalCtx(ctx context.Context, msg string, args ...any) {
+	defaultLogger.Load().logAndExit(ctx, msg, args...)
+}
+
+// Panic logs at panic level without context and then panics with msg
+func Panic(msg string, args ...any) {
+	defaultLogger.Load().logAndPanic(context.Background(), msg, args...)
+}
+
+// PanicCtx logs at panic level with context and then panics with msg
+func PanicCtx(ctx context.Context, msg string, args ...any) {
+	defaultLogger.Load().logAndPanic(ctx, msg, args...)
+}
+
+// ErrorCtxErr logs at error level with context, attaching err as an attribute
+func ErrorCtxErr(ctx context.Context, msg string, err error, args ...any) {
+	defaultLogger.Load().errorCtxErr(ctx, msg, err, args...)
+}
+
+// WithError returns a child of the default logger that includes err in every record
+func WithError(err error) *Logger {
+	return defaultLogger.Load().WithError(err)
+}
+
+// With returns a child of the default logger that includes the given attributes in every record
+func With(attrs ...slog.Attr) *Logger {
+	return defaultLogger.Load().With(attrs...)
+}
+
+// WithContextKeys returns a child of the default logger that also extracts the given context keys
+func WithContextKeys(keys ...string) *Logger {
+	return defaultLogger.Load().WithContextKeys(keys...)
+}
+
+// WithHooks returns a child of the default logger that runs the given hooks around every write
+func WithHooks(before, after Hook) *Logger {
+	return defaultLogger.Load().WithHooks(before, after)
+}
+
+// WithPrefix returns a child of the default logger that prepends "prefix: " to every message
+func WithPrefix(prefix string) *Logger {
+	return defaultLogger.Load().WithPrefix(prefix)
+}
+
+// Group returns a child of the default logger that nests the attributes of its records under name
+func Group(name string) *Logger {
+	return defaultLogger.Load().Group(name)
 }
 
 // ErrAtr creates a slog.Attr for an error
@@ -207,34 +964,162 @@ func ErrAtr(err error) slog.Attr {
 	return slog.Any("error", err)
 }
 
-// InitLogger initializes the loggers with the specified level
-func InitLogger(level slog.Level) {
-	opts := &slog.HandlerOptions{
-		AddSource: true, // Changed to true to include source information
-		Level:     level,
+// NewLogger creates a logger configured by the given options
+func NewLogger(opts ...Option) *Logger {
+	cfg := defaultLoggerConfig()
+	for _, opt := range opts {
+		opt(&cfg)
 	}
 
 	// Use custom handler for better formatting
-	handler := NewCustomHandler(os.Stdout, opts, true)
-	defaultLogger = &Logger{
-		logger:    slog.New(handler),
-		addSource: true,
+	var handler slog.Handler = cfg.newHandler(cfg.writer)
+	if cfg.highWriter != nil {
+		handler = NewSplitHandler(cfg.splitLevel, handler, cfg.newHandler(cfg.highWriter))
+	}
+	return newLogger(handler, cfg)
+}
+
+// NewLoggerWithHandler creates a logger backed by an arbitrary slog.Handler, keeping the
+// context propagation and hooks of Logger. Source attribution is enabled only for a
+// CustomHandler with AddSource set, since other handlers do not expose their options.
+// Options that configure the console handler, such as WithWriter or WithFormat, have no effect
+func NewLoggerWithHandler(h slog.Handler, opts ...Option) *Logger {
+	cfg := defaultLoggerConfig()
+	for _, opt := range opts {
+		opt(&cfg)
 	}
 
-	minHandler := NewCustomHandler(os.Stdout, opts, false)
-	Min = &Logger{
-		logger:    slog.New(minHandler),
-		addSource: false,
+	cfg.addSource = false
+	if ch, ok := h.(*CustomHandler); ok {
+		cfg.addSource = ch.addSource && ch.opts.AddSource
+	}
+	return newLogger(h, cfg)
+}
+
+// newLogger wraps handler in a Logger using the settings from cfg
+func newLogger(handler slog.Handler, cfg loggerConfig) *Logger {
+	return &Logger{
+		logger:      slog.New(handler),
+		addSource:   cfg.addSource,
+		errorKey:    cfg.errorKey,
+		traceIDKey:  cfg.traceIDKey,
+		callerSkip:  cfg.callerSkip,
+		goroutineID: cfg.goroutineID,
+		exitFunc:    cfg.exitFunc,
+		panicFunc:   cfg.panicFunc,
+		extractors:  cfg.extractors,
+		redactor:    newRedactor(cfg.redactedKeys, cfg.masker),
+		timeFormat:  cfg.timeFormat,
+		treeStyle:   cfg.treeStyle,
+
+		contextDeadline:   cfg.contextDeadline,
+		deadlineThreshold: cfg.deadlineThreshold,
 	}
 }
 
+// defaultLevel is the level shared by the package-level loggers
+var defaultLevel slog.LevelVar
+
+// SetDefaultLevel changes the level of the package-level loggers and of the named loggers
+// inheriting it, without recreating them
+func SetDefaultLevel(level slog.Level) {
+	defaultLevel.Set(level)
+}
+
+// InitLogger initializes the loggers with the specified level and options. The package-level
+// loggers are swapped atomically, but the deprecated Min variable is assigned without
+// synchronization, so InitLogger must not run while other goroutines read Min
+func InitLogger(level slog.Level, opts ...Option) {
+	defaultLevel.Set(level)
+	SetDefaultLogger(NewLogger(append([]Option{WithLevel(&defaultLevel)}, opts...)...))
+
+	// Min never tracks source and is colorless unless colors are explicitly requested
+	minOpts := append([]Option{WithLevel(&defaultLevel), WithColor(false)}, opts...)
+	minimal := NewLogger(append(minOpts, WithSource(false))...)
+	SetMinLogger(minimal)
+	Min = minimal
+}
+
+// SetDefaultLogger replaces the logger used by the package-level functions.
+// It is safe to call while other goroutines are logging
+func SetDefaultLogger(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// DefaultLogger returns the logger used by the package-level functions
+func DefaultLogger() *Logger {
+	return defaultLogger.Load()
+}
+
+// SetMinLogger replaces the minimal logger returned by MinLogger, leaving the deprecated
+// Min variable unchanged. It is safe to call while other goroutines are logging
+func SetMinLogger(l *Logger) {
+	minLogger.Store(l)
+}
+
+// MinLogger returns the minimal logger without source tracking
+func MinLogger() *Logger {
+	return minLogger.Load()
+}
+
+// InitLoggerSplit initializes the loggers so that ERROR and above go to os.Stderr and everything else to os.Stdout
+func InitLoggerSplit(level slog.Level, opts ...Option) {
+	split := WithLevelSplitWriter(slog.LevelError, os.Stdout, os.Stderr)
+	InitLogger(level, append([]Option{split}, opts...)...)
+}
+
 func init() {
-	InitLogger(slog.LevelInfo)
+	InitLogger(levelFromEnv())
 	initFileLogger()
 }
 
+// levelFromEnv parses the LOG_LEVEL environment variable, falling back to INFO
+func levelFromEnv() slog.Level {
+	value := os.Getenv("LOG_LEVEL")
+	if value == "" {
+		return slog.LevelInfo
+	}
+
+	level, err := ParseLevel(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sloglog: %v, falling back to INFO\n", err)
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// ParseLevel parses a level name (DEBUG, INFO, WARN, ERROR, PANIC or FATAL, case-insensitive)
+// or an integer level such as "-4"
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "DEBUG":
+		return slog.LevelDebug, nil
+	case "INFO":
+		return slog.LevelInfo, nil
+	case "WARN":
+		return slog.LevelWarn, nil
+	case "ERROR":
+		return slog.LevelError, nil
+	case "PANIC":
+		return LevelPanic, nil
+	case "FATAL":
+		return LevelFatal, nil
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return slog.LevelInfo, fmt.Errorf("unrecognized log level %q", s)
+	}
+	return slog.Level(n), nil
+}
+
 // initFileLogger initializes the file logger
 func initFileLogger() {
+	fileLogger.Store(newFileLogger(FileLoggerOptions{}))
+}
+
+// defaultLogDir returns the log directory used when none is configured
+func defaultLogDir() string {
 	logDir := os.Getenv("LOG_DIR_PATH")
 	if logDir == "" {
 		// Use current working directory of the importing project
@@ -245,90 +1130,379 @@ func initFileLogger() {
 			logDir = filepath.Join(cwd, "external/logs")
 		}
 	}
+	return logDir
+}
+
+// newFileLogger creates a disabled file logger, filling in defaults for unset options
+func newFileLogger(opts FileLoggerOptions) *FileLogger {
+	if opts.Dir == "" {
+		opts.Dir = defaultLogDir()
+	}
+	if opts.BufferSize > 0 && opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	if opts.IndexFile == "" {
+		opts.IndexFile = defaultIndexFile
+	}
+
+	return &FileLogger{
+		opts: opts,
+	}
+}
 
-	fileLogger = &FileLogger{
-		dir:     logDir,
-		enabled: false,
+// EnableFileLogging enables file logging, applying opts on top of the current configuration
+func EnableFileLogging(opts ...FileLoggerOption) {
+	fl := fileLogger.Load()
+	if len(opts) > 0 {
+		fileOpts := fl.opts
+		for _, opt := range opts {
+			opt(&fileOpts)
+		}
+		EnableFileLoggingWithOptions(fileOpts)
+		return
 	}
+	fl.enable()
 }
 
-// EnableFileLogging enables file logging
-func EnableFileLogging() {
-	if fileLogger == nil {
-		initFileLogger()
+// EnableFileLoggingWithOptions enables file logging configured by opts, replacing the previous
+// configuration. Records logged concurrently go to either the previous or the new file logger
+func EnableFileLoggingWithOptions(opts FileLoggerOptions) {
+	fl := newFileLogger(opts)
+	fl.enable()
+	if prev := fileLogger.Swap(fl); prev != nil {
+		prev.Close()
 	}
-	fileLogger.enabled = true
 }
 
-// DisableFileLogging disables file logging
+// DisableFileLogging disables file logging, flushing and closing the current file
 func DisableFileLogging() {
-	if fileLogger != nil {
-		fileLogger.mu.Lock()
-		defer fileLogger.mu.Unlock()
-		if fileLogger.file != nil {
-			fileLogger.file.Close()
-			fileLogger.file = nil
-		}
-		fileLogger.enabled = false
+	fileLogger.Load().Close()
+}
+
+// Flush writes any queued or buffered log entries to the current file
+func (fl *FileLogger) Flush() error {
+	fl.waitAsync()
+
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	if fl.buf == nil {
+		return nil
 	}
+	return fl.buf.Flush()
 }
 
-// getLogFile returns the current log file, creating a new one if needed
-func (fl *FileLogger) getLogFile() (*os.File, error) {
+// Close disables the file logger, writing queued and buffered entries and closing the current file
+func (fl *FileLogger) Close() error {
+	fl.stopAsync()
+	defer fl.background.Wait()
+
 	fl.mu.Lock()
 	defer fl.mu.Unlock()
 
-	if !fl.enabled {
+	var err error
+	if fl.buf != nil {
+		err = fl.buf.Flush()
+		fl.buf = nil
+	}
+	if fl.file != nil {
+		if closeErr := fl.file.Close(); err == nil {
+			err = closeErr
+		}
+		fl.file = nil
+	}
+	if fl.stop != nil {
+		close(fl.stop)
+		fl.stop = nil
+	}
+	fl.enabled.Store(false)
+	return err
+}
+
+// flushLoop periodically flushes the write buffer until stop is closed
+func (fl *FileLogger) flushLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(fl.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fl.Flush()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// getLogFile returns the current log file for now, creating a new one if needed. fl.mu must be held
+func (fl *FileLogger) getLogFile(now time.Time) (*os.File, error) {
+	if !fl.enabled.Load() {
 		return nil, nil
 	}
 
-	today := time.Now().Format("2006-01-02")
+	// The name cannot have changed before recheck, leaving only the size to check
+	if fl.file != nil && now.Before(fl.recheck) && (fl.opts.MaxSize <= 0 || fl.size < fl.opts.MaxSize) {
+		return fl.file, nil
+	}
 
-	if fl.file == nil || fl.date != today {
+	name := fl.opts.logFileName(now)
+	period := fl.opts.Schedule.key(now)
+
+	rotate := true
+	var seq int
+	switch {
+	case fl.file == nil || fl.name != name:
+		// Continue the newest file of the name, e.g. after a restart
+		seq = fl.opts.lastSeq(name)
+	case fl.period != period:
+		// The filename template does not distinguish the periods of the schedule
+		seq = fl.seq + 1
+	case fl.opts.MaxSize > 0 && fl.size >= fl.opts.MaxSize:
+		seq = fl.seq + 1
+	default:
+		rotate = false
+	}
+	fl.recheck = fl.opts.recheckTime(now)
+
+	if rotate {
+		if fl.buf != nil {
+			fl.buf.Flush()
+		}
+		var rotated string
 		if fl.file != nil {
 			fl.file.Close()
+			fl.rotations.Add(1)
+			if fl.opts.Compress {
+				rotated = fl.file.Name()
+			}
 		}
 
-		if err := os.MkdirAll(fl.dir, 0755); err != nil {
+		if err := os.MkdirAll(fl.opts.Dir, 0755); err != nil {
 			return nil, fmt.Errorf("failed to create log directory: %w", err)
 		}
 
-		filename := filepath.Join(fl.dir, fmt.Sprintf("%s.log", today))
+		seq = fl.opts.freeSeq(name, seq)
+		filename := filepath.Join(fl.opts.Dir, seqFileName(name, seq))
 		file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 		if err != nil {
 			return nil, fmt.Errorf("failed to open log file: %w", err)
 		}
 
 		fl.file = file
-		fl.date = today
+		fl.name = name
+		fl.period = period
+		fl.seq = seq
+		fl.size = 0
+		if info, err := file.Stat(); err == nil {
+			fl.size = info.Size()
+		}
+		fl.records = 0
+
+		start := now
+		if fl.opts.FilenameTemplate == "" && seq == 0 {
+			start = fl.opts.Schedule.start(now)
+		}
+		fl.updateIndex(start, filepath.Base(filename))
+
+		if fl.opts.BufferSize > 0 {
+			fl.buf = bufio.NewWriterSize(file, fl.opts.BufferSize)
+			if fl.stop == nil {
+				fl.stop = make(chan struct{})
+				go fl.flushLoop(fl.stop)
+			}
+		}
+
+		cleanup := fl.opts.MaxAge > 0 || fl.opts.MaxCount > 0
+		if rotated != "" || cleanup {
+			prev, done := fl.housekept, make(chan struct{})
+			fl.housekept = done
+			fl.background.Add(1)
+			go fl.housekeep(prev, done, rotated, cleanup, filename, now)
+		}
 	}
 
 	return fl.file, nil
 }
 
-// writeToFile writes log entry to file
+// housekeep compresses the rotated file at path, if any, and then deletes the log files
+// expired by the retention options when cleanup is set. It waits for the call of the previous
+// rotation to close prev and closes done when it returns, so that the calls run in the order
+// of the rotations and the cleanup never sees a file that is still being compressed
+func (fl *FileLogger) housekeep(prev <-chan struct{}, done chan<- struct{}, path string, cleanup bool, current string, now time.Time) {
+	defer fl.background.Done()
+	defer close(done)
+	if prev != nil {
+		<-prev
+	}
+
+	if path != "" {
+		if err := compressLogFile(path); err != nil {
+			fmt.Fprintf(os.Stderr, "sloglog: %v\n", err)
+		}
+	}
+	if cleanup {
+		cleanupLogFiles(fl.opts, current, now)
+	}
+}
+
+// cleanupLogFiles deletes log files in opts.Dir that are older than opts.MaxAge or beyond the
+// opts.MaxCount most recent ones. The current file is never deleted
+func cleanupLogFiles(opts FileLoggerOptions, current string, now time.Time) {
+	entries, err := os.ReadDir(opts.Dir)
+	if err != nil {
+		return
+	}
+
+	type logFile struct {
+		path    string
+		modTime time.Time
+	}
+
+	var files []logFile
+	for _, entry := range entries {
+		if entry.IsDir() || !opts.isLogFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, logFile{
+			path:    filepath.Join(opts.Dir, entry.Name()),
+			modTime: info.ModTime(),
+		})
+	}
+
+	// Newest first
+	slices.SortFunc(files, func(a, b logFile) int {
+		return b.modTime.Compare(a.modTime)
+	})
+
+	for i, f := range files {
+		if f.path == current {
+			continue
+		}
+		expired := opts.MaxAge > 0 && now.Sub(f.modTime) > opts.MaxAge
+		excess := opts.MaxCount > 0 && i >= opts.MaxCount
+		if expired || excess {
+			os.Remove(f.path)
+		}
+	}
+}
+
+// writeToFile writes log entry to file, or queues it when the file logger is asynchronous
 func (fl *FileLogger) writeToFile(entry string) {
-	if !fl.enabled {
+	if !fl.enabled.Load() {
+		return
+	}
+
+	if fl.opts.AsyncBufferSize > 0 {
+		fl.writeAsync(entry)
 		return
 	}
+	fl.writeEntry(entry)
+}
 
-	file, err := fl.getLogFile()
+// writeEntry writes log entry to file. The file is retrieved and written within a single
+// critical section so that rotation or Close cannot invalidate it in between
+func (fl *FileLogger) writeEntry(entry string) {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	now := fl.clock()
+	file, err := fl.getLogFile(now)
 	if err != nil || file == nil {
 		return
 	}
 
-	fl.mu.RLock()
-	defer fl.mu.RUnlock()
+	// The buffer takes the newline separately, while unbuffered entries are written with
+	// a single call so that concurrent writers to the file cannot split the line
+	var n int
+	if fl.buf != nil {
+		n, err = fl.buf.WriteString(entry)
+		if err == nil {
+			err = fl.buf.WriteByte('\n')
+			n++
+		}
+	} else {
+		fl.line = append(append(fl.line[:0], entry...), '\n')
+		n, err = file.Write(fl.line)
+	}
+	fl.size += int64(n)
+	if err == nil {
+		fl.recordWrite(now)
+	}
+}
+
+// clock returns the time of a write, with fl.mu held. Within a second of the last reading of the
+// real clock, the time is derived from it and the monotonic clock alone, which costs half as
+// much as time.Now
+func (fl *FileLogger) clock() time.Time {
+	if fl.now != nil {
+		return fl.now()
+	}
+	if elapsed := time.Since(fl.anchor); elapsed < time.Second {
+		return fl.anchor.Add(elapsed)
+	}
+	fl.anchor = time.Now()
+	return fl.anchor
+}
+
+// recordWrite updates the write statistics after an entry has been written at now
+func (fl *FileLogger) recordWrite(now time.Time) {
+	fl.records++
+	fl.lastWrite = now
+}
 
-	file.WriteString(entry + "\n")
+// FileLoggerStats describes the state of a FileLogger for operational dashboards
+type FileLoggerStats struct {
+	// CurrentFile is the path of the file being written, empty when no file is open
+	CurrentFile string
+	// FileSizeBytes is the size of the current file on disk, excluding buffered entries
+	FileSizeBytes int64
+	// RecordsWritten is the number of records written since the last rotation
+	RecordsWritten int64
+	// LastWriteTime is the time of the last write, zero if nothing was written yet
+	LastWriteTime time.Time
+	// RotationCount is the number of times the file has been rotated
+	RotationCount int64
+	// DroppedRecords is the number of records dropped because the async queue was full
+	DroppedRecords int64
 }
 
-// formatLogEntry formats a log record for file output
-func (l *Logger) formatLogEntry(record slog.Record) string {
+// Stats returns the current file logging statistics
+func (fl *FileLogger) Stats() FileLoggerStats {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	stats := FileLoggerStats{
+		RecordsWritten: fl.records,
+		LastWriteTime:  fl.lastWrite,
+		RotationCount:  fl.rotations.Load(),
+		DroppedRecords: fl.dropped.Load(),
+	}
+	if fl.file != nil {
+		stats.CurrentFile = fl.file.Name()
+		// Stat the file rather than tracking the size to account for external appends
+		if info, err := fl.file.Stat(); err == nil {
+			stats.FileSizeBytes = info.Size()
+		}
+	}
+	return stats
+}
+
+// GetFileLoggerStats returns the statistics of the package-level file logger
+func GetFileLoggerStats() FileLoggerStats {
+	return fileLogger.Load().Stats()
+}
+
+// formatLogEntry formats a log record for file output, writing the keys of top without the
+// prefix of the open groups
+func (l *Logger) formatLogEntry(top []slog.Attr, record slog.Record) string {
 	var parts []string
 
 	// Format timestamp in a more readable format
-	timestamp := record.Time.Format("2006-01-02 15:04:05.000")
+	timestamp := formatTimestamp(record.Time, l.timeFormat, "2006-01-02 15:04:05.000")
 
 	// Format level with fixed width and color-like indicators
 	level := formatLevel(record.Level)
@@ -338,23 +1512,68 @@ func (l *Logger) formatLogEntry(record slog.Record) string {
 	parts = append(parts, mainLine)
 
 	// Add attributes on separate indented lines if present
+	branch, last := l.treeStyle.prefixes()
 	var attrs []string
+	for i, groupAttrs := range l.attrs {
+		prefix := groupPrefix(l.groups[:i])
+		for _, a := range groupAttrs {
+			attrs = append(attrs, fmt.Sprintf("%s%s%s: %s", branch, prefix, a.Key, a.Value.String()))
+		}
+	}
+	for _, a := range top {
+		attrs = append(attrs, fmt.Sprintf("%s%s: %s", branch, a.Key, a.Value.String()))
+	}
+	prefix := groupPrefix(l.groups)
 	record.Attrs(func(a slog.Attr) bool {
-		attrs = append(attrs, fmt.Sprintf("  ├─ %s: %s", a.Key, a.Value.String()))
+		key := a.Key
+		if key != "source" { // Source stays at the top level like on the console
+			key = prefix + key
+		}
+		attrs = append(attrs, fmt.Sprintf("%s%s: %s", branch, key, a.Value.String()))
 		return true
 	})
 
 	if len(attrs) > 0 {
 		// Change the last attribute prefix to indicate end
-		if len(attrs) > 1 {
-			attrs[len(attrs)-1] = strings.Replace(attrs[len(attrs)-1], "├─", "└─", 1)
-		}
+		attrs[len(attrs)-1] = last + strings.TrimPrefix(attrs[len(attrs)-1], branch)
 		parts = append(parts, attrs...)
 	}
 
 	return strings.Join(parts, "\n")
 }
 
+// TreeStyle selects the characters drawing the attribute tree of file entries
+type TreeStyle int
+
+const (
+	// TreeStyleUnicode draws attributes with ├─ and the last one with └─ (default)
+	TreeStyleUnicode TreeStyle = iota
+	// TreeStyleASCII draws attributes with +-- for terminals without box-drawing characters
+	TreeStyleASCII
+)
+
+// prefixes returns the line prefixes of attributes and of the last attribute
+func (s TreeStyle) prefixes() (branch, last string) {
+	if s == TreeStyleASCII {
+		return "  +-- ", "  +-- "
+	}
+	return "  ├─ ", "  └─ "
+}
+
+// TimestampUnixMilli is a timestamp format emitting the Unix time in milliseconds as an integer
+const TimestampUnixMilli = "unix_ms"
+
+// formatTimestamp formats t with layout, or with def when layout is empty
+func formatTimestamp(t time.Time, layout, def string) string {
+	switch layout {
+	case "":
+		return t.Format(def)
+	case TimestampUnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+	return t.Format(layout)
+}
+
 // formatLevel formats the log level with consistent width
 func formatLevel(level slog.Level) string {
 	switch level {
@@ -366,16 +1585,42 @@ func formatLevel(level slog.Level) string {
 		return "WARN"
 	case slog.LevelError:
 		return "ERROR"
+	case LevelPanic:
+		return "PANIC"
+	case LevelFatal:
+		return "FATAL"
 	default:
 		return level.String()
 	}
 }
 
+// Format selects how CustomHandler renders records
+type Format int
+
+const (
+	// FormatText renders each record as a single human-readable line (default)
+	FormatText Format = iota
+	// FormatJSON renders each record as one JSON object per line
+	FormatJSON
+)
+
 // CustomHandler implements slog.Handler for better formatting
 type CustomHandler struct {
 	opts      slog.HandlerOptions
 	writer    io.Writer
 	addSource bool
+	color     bool
+	colors    ColorScheme
+	format    Format
+	// timeFormat is the timestamp layout or TimestampUnixMilli, "" for the default of the format
+	timeFormat string
+	// Keys of the built-in JSON fields, "" for the log/slog defaults
+	timeKey, levelKey, messageKey string
+
+	// groupStack holds the open groups, outermost first
+	groupStack []string
+	// attrs[i] holds the attributes added while i groups were open
+	attrs [][]slog.Attr
 }
 
 // NewCustomHandler creates a new custom handler
@@ -387,9 +1632,17 @@ func NewCustomHandler(w io.Writer, opts *slog.HandlerOptions, addSource bool) *C
 		opts:      *opts,
 		writer:    w,
 		addSource: addSource,
+		color:     isTerminal(w),
+		colors:    DefaultColorScheme(),
 	}
 }
 
+// isTerminal reports whether w is a terminal, in which case colors are enabled by default
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
 // Enabled reports whether the handler handles records at the given level
 func (h *CustomHandler) Enabled(ctx context.Context, level slog.Level) bool {
 	minLevel := slog.LevelInfo
@@ -406,15 +1659,26 @@ func (h *CustomHandler) Handle(ctx context.Context, r slog.Record) error {
 		return nil
 	}
 
+	if h.format == FormatJSON {
+		return h.handleJSON(r)
+	}
+
 	// Format timestamp with full date and timezone
-	timestamp := r.Time.Format("2006-01-02 15:04:05 MST")
+	timestamp := formatTimestamp(r.Time, h.timeFormat, "2006-01-02 15:04:05 MST")
 
 	// Format level with colors for console
-	level := formatLevelWithColor(r.Level)
+	level := "[" + formatLevel(r.Level) + "]"
+	if h.color {
+		level = h.colors.formatLevel(r.Level)
+	}
 
 	// Build the main log line
-	var parts []string
-	mainLine := fmt.Sprintf("%s %s %s", timestamp, level, r.Message)
+	buf := make([]byte, 0, 256)
+	buf = append(buf, timestamp...)
+	buf = append(buf, ' ')
+	buf = append(buf, level...)
+	buf = append(buf, ' ')
+	buf = append(buf, r.Message...)
 
 	// Add source information if enabled
 	if h.addSource {
@@ -422,7 +1686,8 @@ func (h *CustomHandler) Handle(ctx context.Context, r slog.Record) error {
 			// Source info is already in attributes
 			r.Attrs(func(a slog.Attr) bool {
 				if a.Key == "source" {
-					mainLine += fmt.Sprintf(" %s", a.Value.String())
+					buf = append(buf, ' ')
+					buf = appendTextValue(buf, a.Value)
 					return false // Don't process this attribute again
 				}
 				return true
@@ -430,56 +1695,116 @@ func (h *CustomHandler) Handle(ctx context.Context, r slog.Record) error {
 		}
 	}
 
-	parts = append(parts, mainLine)
-
 	// Add other attributes on the same line for console (more compact)
-	var attrs []string
+	for i, groupAttrs := range h.attrs {
+		prefix := groupPrefix(h.groupStack[:i])
+		for _, a := range groupAttrs {
+			buf = appendTextField(buf, prefix, a)
+		}
+	}
+	prefix := groupPrefix(h.groupStack)
 	r.Attrs(func(a slog.Attr) bool {
 		if a.Key != "source" { // Skip source as it's already handled
-			attrs = append(attrs, fmt.Sprintf("%s=%s", a.Key, a.Value.String()))
+			buf = appendTextField(buf, prefix, a)
 		}
 		return true
 	})
 
-	if len(attrs) > 0 {
-		parts[0] += " " + strings.Join(attrs, " ")
-	}
-
 	// Write to output
-	fmt.Fprintln(h.writer, strings.Join(parts, "\n"))
-	return nil
+	buf = append(buf, '\n')
+	_, err := h.writer.Write(buf)
+	return err
 }
 
-// WithAttrs returns a new Handler whose attributes consist of h's attributes followed by attrs
-func (h *CustomHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return h // Simplified implementation
+// groupPrefix returns the dot-separated key prefix for the given groups
+func groupPrefix(groups []string) string {
+	if len(groups) == 0 {
+		return ""
+	}
+	return strings.Join(groups, ".") + "."
 }
 
-// WithGroup returns a new Handler with the given group appended to the receiver's existing groups
-func (h *CustomHandler) WithGroup(name string) slog.Handler {
-	return h // Simplified implementation
+// appendTextAttr appends a as key=value with the given key prefix, flattening nested groups
+func appendTextAttr(attrs []string, prefix string, a slog.Attr) []string {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return attrs
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			attrs = appendTextAttr(attrs, prefix, ga)
+		}
+		return attrs
+	}
+
+	return append(attrs, prefix+a.Key+"="+a.Value.String())
 }
 
-// formatLevelWithColor formats the log level with ANSI colors for console
-func formatLevelWithColor(level slog.Level) string {
-	const (
-		colorReset  = "\033[0m"
-		colorRed    = "\033[31m"
-		colorYellow = "\033[33m"
-		colorBlue   = "\033[34m"
-		colorGray   = "\033[37m"
-	)
+// appendTextField appends a to buf as " key=value" with the given key prefix, flattening nested groups
+func appendTextField(buf []byte, prefix string, a slog.Attr) []byte {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return buf
+	}
 
-	switch level {
-	case slog.LevelDebug:
-		return colorGray + "[DEBUG]" + colorReset
-	case slog.LevelInfo:
-		return colorBlue + "[INFO]" + colorReset
-	case slog.LevelWarn:
-		return colorYellow + "[WARN]" + colorReset
-	case slog.LevelError:
-		return colorRed + "[ERROR]" + colorReset
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			buf = appendTextField(buf, prefix, ga)
+		}
+		return buf
+	}
+
+	buf = append(buf, ' ')
+	buf = append(buf, prefix...)
+	buf = append(buf, a.Key...)
+	buf = append(buf, '=')
+	return appendTextValue(buf, a.Value)
+}
+
+// appendTextValue appends v to buf formatted as by slog.Value.String
+func appendTextValue(buf []byte, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindString:
+		return append(buf, v.String()...)
+	case slog.KindInt64:
+		return strconv.AppendInt(buf, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(buf, v.Uint64(), 10)
+	case slog.KindBool:
+		return strconv.AppendBool(buf, v.Bool())
 	default:
-		return fmt.Sprintf("[%s]", level.String())
+		return append(buf, v.String()...)
+	}
+}
+
+// WithAttrs returns a new Handler whose attributes consist of h's attributes followed by attrs.
+// The attrs are scoped under the groups open on h
+func (h *CustomHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	depth := len(h.groupStack)
+	h2.attrs = make([][]slog.Attr, max(len(h.attrs), depth+1))
+	copy(h2.attrs, h.attrs)
+	current := h2.attrs[depth]
+	h2.attrs[depth] = append(current[:len(current):len(current)], attrs...)
+	return &h2
+}
+
+// WithGroup returns a new Handler with the given group appended to the receiver's existing groups
+func (h *CustomHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
 	}
+	h2 := *h
+	h2.groupStack = append(h.groupStack[:len(h.groupStack):len(h.groupStack)], name)
+	return &h2
 }
@@ -0,0 +1,1574 @@
+package sloglog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// newBufferLogger creates a colorless logger writing its console output to the returned buffer
+func newBufferLogger(opts ...Option) (*Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return NewLogger(append([]Option{WithWriter(&buf), WithColor(false)}, opts...)...), &buf
+}
+
+// syncBuffer is a bytes.Buffer that can be written by concurrent loggers
+type syncBuffer struct {
+	mu sync.Mutex
+	bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Buffer.Write(p)
+}
+
+// lines returns the non-empty lines of the buffer
+func lines(buf *bytes.Buffer) []string {
+	var out []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// useDefaultLogger installs logger as the default logger for the duration of the test
+func useDefaultLogger(t *testing.T, logger *Logger) {
+	t.Helper()
+	prev := DefaultLogger()
+	SetDefaultLogger(logger)
+	t.Cleanup(func() { SetDefaultLogger(prev) })
+}
+
+// enableTestFileLogging enables the package-level file logger in a temporary directory,
+// disabling it again when the test finishes, and returns the directory
+func enableTestFileLogging(t *testing.T, opts FileLoggerOptions) string {
+	t.Helper()
+	opts.Dir = t.TempDir()
+	EnableFileLoggingWithOptions(opts)
+	t.Cleanup(DisableFileLogging)
+	return opts.Dir
+}
+
+// readLogFiles returns the contents of the log files in dir
+func readLogFiles(t *testing.T, dir string) string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sb.Write(data)
+	}
+	return sb.String()
+}
+
+func TestLoggerWith(t *testing.T) {
+	dir := enableTestFileLogging(t, FileLoggerOptions{})
+	logger, buf := newBufferLogger()
+	child := logger.With(slog.String("svc", "payments"))
+
+	child.Info("first")
+	child.WarnCtx(context.Background(), "second", slog.Int("attempt", 2))
+	child.Error("third")
+	logger.Info("parent")
+
+	got := lines(buf)
+	if len(got) != 4 {
+		t.Fatalf("got %d console lines, want 4:\n%s", len(got), buf)
+	}
+	for _, line := range got[:3] {
+		if !strings.Contains(line, "svc=payments") {
+			t.Errorf("child record %q does not carry svc=payments", line)
+		}
+	}
+	if strings.Contains(got[3], "svc=payments") {
+		t.Errorf("parent record %q carries the attribute of the child", got[3])
+	}
+
+	file := readLogFiles(t, dir)
+	if n := strings.Count(file, "svc: payments"); n != 3 {
+		t.Errorf("file output carries svc: payments %d times, want 3:\n%s", n, file)
+	}
+}
+
+func TestGroupFuncJSON(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON), WithSource(false))
+
+	logger.GroupFunc("db", func(lg *Logger) {
+		lg.Info("connected", slog.String("host", "primary"), slog.Int("port", 5432))
+		lg.Group("pool").Info("sized", slog.Int("size", 10))
+	})
+	logger.Info("outside", slog.Int("n", 1))
+
+	records := decodeJSONLines(t, buf)
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	want := []map[string]any{
+		{"db": map[string]any{"host": "primary", "port": float64(5432)}},
+		{"db": map[string]any{"pool": map[string]any{"size": float64(10)}}},
+		{"n": float64(1)},
+	}
+	for i, record := range records {
+		for _, key := range []string{"time", "level", "msg"} {
+			delete(record, key)
+		}
+		if !reflect.DeepEqual(record, want[i]) {
+			t.Errorf("record %d attributes = %v, want %v", i, record, want[i])
+		}
+	}
+}
+
+func TestGroupFileOutput(t *testing.T) {
+	dir := enableTestFileLogging(t, FileLoggerOptions{})
+	logger, _ := newBufferLogger(WithSource(false))
+
+	logger.Group("db").Group("pool").Info("sized", slog.Int("size", 10))
+
+	if file := readLogFiles(t, dir); !strings.Contains(file, "└─ db.pool.size: 10") {
+		t.Errorf("file output does not nest size under db.pool:\n%s", file)
+	}
+}
+
+func TestLoggerGroupFileOutput(t *testing.T) {
+	dir := enableTestFileLogging(t, FileLoggerOptions{})
+	logger, _ := newBufferLogger(WithSource(false))
+
+	logger.With(slog.Int("a", 1)).Group("g").With(slog.Int("b", 2)).Info("grouped", slog.Int("c", 3))
+
+	file := readLogFiles(t, dir)
+	for _, want := range []string{"├─ a: 1", "├─ g.b: 2", "└─ g.c: 3"} {
+		if !strings.Contains(file, want) {
+			t.Errorf("file output does not contain %q:\n%s", want, file)
+		}
+	}
+}
+
+func TestGroupContextAttrsTopLevel(t *testing.T) {
+	ctx := context.WithValue(context.Background(), TraceIDContextKey, "trace-1")
+	custom, customBuf := newBufferLogger(WithFormat(FormatJSON), WithSource(false))
+	var opaqueBuf bytes.Buffer
+	opaque := NewLoggerWithHandler(slog.NewJSONHandler(&opaqueBuf, nil))
+
+	tests := []struct {
+		name   string
+		logger *Logger
+		buf    *bytes.Buffer
+	}{
+		{"CustomHandler", custom, customBuf},
+		{"slog.JSONHandler", opaque, &opaqueBuf},
+	}
+	for _, tt := range tests {
+		tt.logger.With(slog.Int("a", 1)).Group("db").With(slog.Int("b", 2)).Group("pool").
+			InfoCtx(ctx, "sized", slog.Int("size", 10))
+
+		record := decodeJSONLines(t, tt.buf)[0]
+		for _, key := range []string{"time", "level", "msg"} {
+			delete(record, key)
+		}
+		want := map[string]any{
+			"a":        float64(1),
+			"trace_id": "trace-1",
+			"db":       map[string]any{"b": float64(2), "pool": map[string]any{"size": float64(10)}},
+		}
+		if !reflect.DeepEqual(record, want) {
+			t.Errorf("%s: record attributes = %v, want %v", tt.name, record, want)
+		}
+	}
+}
+
+func TestGroupContextAttrsTopLevelText(t *testing.T) {
+	dir := enableTestFileLogging(t, FileLoggerOptions{})
+	logger, buf := newBufferLogger(WithSource(false), WithGoroutineID(true))
+	var tee bytes.Buffer
+	ctx := context.WithValue(context.Background(), TraceIDContextKey, "trace-1")
+
+	Tee(logger.Group("db"), &tee).InfoCtx(ctx, "connected", slog.String("host", "primary"))
+
+	for name, out := range map[string]string{"console": buf.String(), "tee": tee.String()} {
+		if !strings.Contains(out, " trace_id=trace-1 goroutine_id=") || !strings.Contains(out, "db.host=primary") {
+			t.Errorf("%s output %q, want trace_id and goroutine_id outside of db", name, out)
+		}
+	}
+	file := readLogFiles(t, dir)
+	for _, want := range []string{"├─ trace_id: trace-1", "├─ goroutine_id: ", "└─ db.host: primary"} {
+		if !strings.Contains(file, want) {
+			t.Errorf("file output does not contain %q:\n%s", want, file)
+		}
+	}
+}
+
+func TestGroupBeforeHookSeesContextAttrs(t *testing.T) {
+	var traceID string
+	logger, buf := newBufferLogger(WithSource(false))
+	logger = logger.WithHooks(func(r slog.Record) slog.Record {
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == TraceIDKey {
+				traceID = a.Value.String()
+			}
+			return true
+		})
+		return r
+	}, nil)
+	ctx := context.WithValue(context.Background(), TraceIDContextKey, "trace-1")
+
+	logger.Group("db").InfoCtx(ctx, "connected")
+
+	if traceID != "trace-1" {
+		t.Errorf("before hook saw trace_id %q, want trace-1", traceID)
+	}
+	if !strings.Contains(buf.String(), " trace_id=trace-1") {
+		t.Errorf("output %q, want trace_id at the top level", buf)
+	}
+}
+
+func TestPackageWith(t *testing.T) {
+	logger, buf := newBufferLogger()
+	useDefaultLogger(t, logger)
+
+	With(slog.String("svc", "payments")).Info("hello")
+
+	if !strings.Contains(buf.String(), "svc=payments") {
+		t.Errorf("output %q does not carry svc=payments", buf)
+	}
+}
+
+func TestWithErrorNil(t *testing.T) {
+	logger, buf := newBufferLogger()
+	if got := logger.WithError(nil); got != logger {
+		t.Error("WithError(nil) returned a new logger")
+	}
+
+	logger.ErrorCtxErr(context.Background(), "failed", nil)
+	if strings.Contains(buf.String(), "error=") {
+		t.Errorf("output %q carries an error attribute for a nil error", buf)
+	}
+}
+
+func TestErrorCtxErrWrapped(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON), WithErrorKey("err"))
+	err := fmt.Errorf("charge card: %w", fmt.Errorf("read response: %w", io.ErrUnexpectedEOF))
+
+	logger.ErrorCtxErr(context.Background(), "payment failed", err)
+	logger.WithError(err).Warn("retrying")
+
+	records := decodeJSONLines(t, buf)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	wantChain := []any{"read response: unexpected EOF", "unexpected EOF"}
+	for _, record := range records {
+		if got := record["err"]; got != err.Error() {
+			t.Errorf("err = %v, want %q", got, err.Error())
+		}
+		if got := record["err.chain"]; !reflect.DeepEqual(got, wantChain) {
+			t.Errorf("err.chain = %v, want %v", got, wantChain)
+		}
+	}
+}
+
+func TestErrorChain(t *testing.T) {
+	base := errors.New("base")
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"unwrapped", base, nil},
+		{"single level", fmt.Errorf("outer: %w", base), []string{"base"}},
+		{"multi level", fmt.Errorf("a: %w", fmt.Errorf("b: %w", fmt.Errorf("c: %w", base))), []string{"b: c: base", "c: base", "base"}},
+		{"joined", errors.Join(errors.New("first"), fmt.Errorf("second: %w", base)), []string{"first", "second: base", "base"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorChain(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errorChain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFatalWritesBeforeExit(t *testing.T) {
+	var buf bytes.Buffer
+	var written bool
+	exitCode := -1
+	logger := NewLogger(WithWriter(&buf), WithExitFunc(func(code int) {
+		written = strings.Contains(buf.String(), "shutting down")
+		exitCode = code
+	}))
+
+	logger.Fatal("shutting down", slog.String("reason", "test"))
+
+	if exitCode != 1 {
+		t.Fatalf("exit code = %d, want 1", exitCode)
+	}
+	if !written {
+		t.Errorf("record was not written before exit, output %q", buf.String())
+	}
+}
+
+func TestPanicWritesBeforePanicking(t *testing.T) {
+	var buf bytes.Buffer
+	var panicMsg string
+	logger := NewLogger(WithWriter(&buf), WithPanicFunc(func(msg string) {
+		if !strings.Contains(buf.String(), "invariant violated") {
+			t.Errorf("record was not written before panicking, output %q", buf.String())
+		}
+		panicMsg = msg
+	}))
+
+	logger.PanicCtx(context.Background(), "invariant violated")
+
+	if panicMsg != "invariant violated" {
+		t.Errorf("panic message = %q, want %q", panicMsg, "invariant violated")
+	}
+}
+
+func TestPanicDefault(t *testing.T) {
+	logger, _ := newBufferLogger()
+	defer func() {
+		if v := recover(); v != "boom" {
+			t.Errorf("recovered %v, want boom", v)
+		}
+	}()
+	logger.Panic("boom")
+	t.Error("Panic returned")
+}
+
+func TestPackageFatalAndPanic(t *testing.T) {
+	var exits, panics int
+	logger, buf := newBufferLogger(
+		WithExitFunc(func(int) { exits++ }),
+		WithPanicFunc(func(string) { panics++ }),
+	)
+	useDefaultLogger(t, logger)
+
+	Fatal("fatal")
+	FatalCtx(context.Background(), "fatal ctx")
+	Panic("panic")
+	PanicCtx(context.Background(), "panic ctx")
+
+	if exits != 2 || panics != 2 {
+		t.Errorf("exits = %d, panics = %d, want 2 each", exits, panics)
+	}
+	if n := len(lines(buf)); n != 4 {
+		t.Errorf("got %d records, want 4:\n%s", n, buf)
+	}
+}
+
+// tenant is a fmt.Stringer stored in contexts by the tests
+type tenant struct{ name string }
+
+func (t tenant) String() string { return "tenant-" + t.name }
+
+func TestWithContextKeys(t *testing.T) {
+	logger, buf := newBufferLogger()
+	logger = logger.WithContextKeys("user_id", "tenant")
+
+	ctx := context.WithValue(context.Background(), "user_id", "u-42")
+	ctx = context.WithValue(ctx, "tenant", tenant{"acme"})
+	logger.InfoCtx(ctx, "std context")
+
+	fhCtx := &fasthttp.RequestCtx{}
+	fhCtx.SetUserValue("user_id", "u-43")
+	logger.InfoCtx(fhCtx, "fasthttp context")
+
+	logger.InfoCtx(context.Background(), "no values")
+
+	got := lines(buf)
+	if len(got) != 3 {
+		t.Fatalf("got %d records, want 3:\n%s", len(got), buf)
+	}
+	for _, want := range []string{"user_id=u-42", "tenant=tenant-acme"} {
+		if !strings.Contains(got[0], want) {
+			t.Errorf("record %q is missing %s", got[0], want)
+		}
+	}
+	if !strings.Contains(got[1], "user_id=u-43") {
+		t.Errorf("record %q is missing user_id=u-43", got[1])
+	}
+	if strings.Contains(got[2], "user_id=") {
+		t.Errorf("record %q carries a user_id without one in the context", got[2])
+	}
+}
+
+func TestHooksEnrich(t *testing.T) {
+	logger, buf := newBufferLogger()
+	logger = logger.WithHooks(func(r slog.Record) slog.Record {
+		r.AddAttrs(slog.String("region", "eu-west-1"))
+		return r
+	}, nil)
+
+	logger.Info("enriched")
+
+	if !strings.Contains(buf.String(), "region=eu-west-1") {
+		t.Errorf("output %q is missing the attribute added by the before hook", buf)
+	}
+}
+
+func TestHooksSuppress(t *testing.T) {
+	logger, buf := newBufferLogger()
+	var after int
+	logger = logger.WithHooks(func(r slog.Record) slog.Record {
+		if r.Message == "noisy" {
+			return slog.Record{}
+		}
+		return r
+	}, func(r slog.Record) slog.Record {
+		after++
+		return r
+	})
+
+	logger.Info("noisy")
+	logger.Info("kept")
+
+	if strings.Contains(buf.String(), "noisy") || !strings.Contains(buf.String(), "kept") {
+		t.Errorf("output %q, want only the kept record", buf)
+	}
+	if after != 1 {
+		t.Errorf("after hook ran %d times, want 1 for the written record", after)
+	}
+}
+
+func TestHooksAfterWrite(t *testing.T) {
+	logger, buf := newBufferLogger()
+	var written []string
+	var order []string
+	logger = logger.WithHooks(func(r slog.Record) slog.Record {
+		order = append(order, "parent")
+		return r
+	}, nil).WithHooks(func(r slog.Record) slog.Record {
+		order = append(order, "child")
+		r.AddAttrs(slog.Bool("hooked", true))
+		return r
+	}, func(r slog.Record) slog.Record {
+		// The record has reached the output by the time the after hook runs
+		written = append(written, buf.String())
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == "hooked" {
+				order = append(order, "after saw hooked")
+			}
+			return true
+		})
+		return r
+	})
+
+	logger.Error("observed")
+
+	if len(written) != 1 || !strings.Contains(written[0], "observed") {
+		t.Errorf("output seen by the after hook = %q, want the written record", written)
+	}
+	if want := []string{"parent", "child", "after saw hooked"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("hook order = %q, want %q", order, want)
+	}
+}
+
+func TestLogFuncLazy(t *testing.T) {
+	logger, buf := newBufferLogger(WithLevel(slog.LevelInfo))
+	var calls int
+	fn := func() []slog.Attr {
+		calls++
+		return []slog.Attr{slog.Int("size", 10)}
+	}
+
+	logger.DebugFunc("disabled", fn)
+	logger.DebugCtxFunc(context.Background(), "disabled", fn)
+	if calls != 0 {
+		t.Fatalf("fn called %d times for disabled levels", calls)
+	}
+
+	logger.InfoFunc("enabled", fn)
+	logger.ErrorCtxFunc(context.Background(), "enabled", fn)
+	if calls != 2 {
+		t.Fatalf("fn called %d times for enabled levels, want 2", calls)
+	}
+
+	for _, line := range lines(buf) {
+		if !strings.Contains(line, "size=10") {
+			t.Errorf("record %q is missing the attributes of fn", line)
+		}
+		if !strings.Contains(line, "service_test.go") {
+			t.Errorf("record %q is not attributed to the call site", line)
+		}
+	}
+}
+
+func TestDebugFuncDisabledAllocs(t *testing.T) {
+	logger, _ := newBufferLogger(WithLevel(slog.LevelInfo))
+	fn := func() []slog.Attr { return []slog.Attr{slog.Int("size", 10)} }
+
+	if allocs := testing.AllocsPerRun(100, func() { logger.DebugFunc("disabled", fn) }); allocs != 0 {
+		t.Errorf("DebugFunc allocates %v times per call at a disabled level, want 0", allocs)
+	}
+}
+
+// expensiveAttrs stands in for costly attribute construction
+func expensiveAttrs() []slog.Attr {
+	return []slog.Attr{slog.String("dump", strings.Repeat("x", 256))}
+}
+
+func BenchmarkDebugDisabledEager(b *testing.B) {
+	logger, _ := newBufferLogger(WithLevel(slog.LevelInfo))
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.Debug("disabled", expensiveAttrs()[0])
+	}
+}
+
+func BenchmarkDebugFuncDisabled(b *testing.B) {
+	logger, _ := newBufferLogger(WithLevel(slog.LevelInfo))
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.DebugFunc("disabled", expensiveAttrs)
+	}
+}
+
+// newGroupedHandler returns a handler with attributes added before, between and after two groups
+func newGroupedHandler(w io.Writer, format Format) slog.Handler {
+	h := NewCustomHandler(w, nil, false)
+	h.format = format
+	return h.WithAttrs([]slog.Attr{slog.String("app", "billing")}).
+		WithGroup("db").
+		WithAttrs([]slog.Attr{slog.String("host", "primary")}).
+		WithGroup("pool")
+}
+
+func TestCustomHandlerGroupsText(t *testing.T) {
+	var buf bytes.Buffer
+	slog.New(newGroupedHandler(&buf, FormatText)).Info("connected", slog.Int("size", 10))
+
+	line := strings.TrimSpace(buf.String())
+	if want := "app=billing db.host=primary db.pool.size=10"; !strings.HasSuffix(line, want) {
+		t.Errorf("output %q, want attributes %q", line, want)
+	}
+}
+
+func TestCustomHandlerNestedGroupAttr(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCustomHandler(&buf, nil, false).WithGroup("req")
+	slog.New(h).Info("handled", slog.Group("user", slog.String("id", "u1"), slog.Group("org", slog.Int("id", 7))))
+
+	if want := "req.user.id=u1 req.user.org.id=7"; !strings.HasSuffix(strings.TrimSpace(buf.String()), want) {
+		t.Errorf("output %q, want attributes %q", buf.String(), want)
+	}
+}
+
+// createAgedLogFiles creates daily log files for the n days before now, the newest first,
+// with modification times matching their names, and returns their paths
+func createAgedLogFiles(t *testing.T, dir string, now time.Time, n int) []string {
+	t.Helper()
+	paths := make([]string, n)
+	for i := range n {
+		day := now.AddDate(0, 0, -i)
+		paths[i] = filepath.Join(dir, day.Format("2006-01-02")+".log")
+		if err := os.WriteFile(paths[i], []byte("record\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(paths[i], day, day); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func TestCleanupLogFilesMaxCount(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	paths := createAgedLogFiles(t, dir, now, 10)
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cleanupLogFiles(FileLoggerOptions{Dir: dir, MaxCount: 5}, paths[0], now)
+
+	for i, path := range paths {
+		_, err := os.Stat(path)
+		if kept := err == nil; kept != (i < 5) {
+			t.Errorf("%s kept = %v, want %v", filepath.Base(path), kept, i < 5)
+		}
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("file not matching the log naming pattern was deleted: %v", err)
+	}
+}
+
+func TestCleanupLogFilesMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	paths := createAgedLogFiles(t, dir, now, 6)
+
+	// The current file is kept even when it is older than MaxAge
+	cleanupLogFiles(FileLoggerOptions{Dir: dir, MaxAge: 84 * time.Hour}, paths[5], now)
+
+	for i, path := range paths {
+		_, err := os.Stat(path)
+		want := i <= 3 || i == 5
+		if kept := err == nil; kept != want {
+			t.Errorf("%s kept = %v, want %v", filepath.Base(path), kept, want)
+		}
+	}
+}
+
+func TestCloseWaitsForHousekeeping(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		clock := &testClock{now: time.Now()}
+		dir := t.TempDir()
+		expired := createAgedLogFiles(t, dir, clock.now.AddDate(0, 0, -10), 200)
+		fl := newTestFileLogger(t, FileLoggerOptions{Dir: dir, Compress: compress, MaxAge: 7 * 24 * time.Hour}, clock)
+
+		var rotated []string
+		for range 3 {
+			fl.writeToFile("record")
+			rotated = append(rotated, fl.opts.logFileName(clock.now)+".log")
+			clock.now = clock.now.AddDate(0, 0, 1)
+		}
+		fl.writeToFile("current")
+		fl.Close()
+
+		for _, path := range expired {
+			if _, err := os.Stat(path); err == nil {
+				t.Fatalf("compress %t: %s not deleted before Close returned", compress, filepath.Base(path))
+			}
+		}
+		want := []string{fl.opts.logFileName(clock.now) + ".log"}
+		if !compress {
+			want = append(rotated, want...)
+		}
+		if got := logFileNames(t, dir); !slices.Equal(got, want) {
+			t.Errorf("compress %t: uncompressed log files after Close = %v, want %v", compress, got, want)
+		}
+		if compress {
+			if gz, _ := filepath.Glob(filepath.Join(dir, "*.log.gz")); len(gz) != len(rotated) {
+				t.Errorf("compressed log files after Close = %v, want the %d rotated ones", gz, len(rotated))
+			}
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want slog.Level
+	}{
+		{"DEBUG", slog.LevelDebug},
+		{"debug", slog.LevelDebug},
+		{"Info", slog.LevelInfo},
+		{"WARN", slog.LevelWarn},
+		{"error", slog.LevelError},
+		{" ERROR ", slog.LevelError},
+		{"panic", LevelPanic},
+		{"FATAL", LevelFatal},
+		{"-4", slog.LevelDebug},
+		{"8", slog.LevelError},
+		{"3", slog.Level(3)},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "verbose", "1.5"} {
+		if got, err := ParseLevel(in); err == nil || got != slog.LevelInfo {
+			t.Errorf("ParseLevel(%q) = %v, %v, want INFO and an error", in, got, err)
+		}
+	}
+}
+
+func TestLevelFromEnv(t *testing.T) {
+	tests := []struct {
+		value string
+		want  slog.Level
+	}{
+		{"", slog.LevelInfo},
+		{"debug", slog.LevelDebug},
+		{"-4", slog.LevelDebug},
+		{"WARN", slog.LevelWarn},
+		{"loud", slog.LevelInfo},
+	}
+	for _, tt := range tests {
+		t.Setenv("LOG_LEVEL", tt.value)
+		if got := levelFromEnv(); got != tt.want {
+			t.Errorf("LOG_LEVEL=%q: level = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRequestIDsFromContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), SpanIDContextKey, "span-only")
+	if got := GetSpanID(ctx); got != "span-only" {
+		t.Errorf("GetSpanID = %q, want span-only", got)
+	}
+	if got := GetTraceID(ctx); got != "" {
+		t.Errorf("GetTraceID = %q, want none", got)
+	}
+	if got := GetCorrelationID(ctx); got != "" {
+		t.Errorf("GetCorrelationID = %q, want none", got)
+	}
+
+	ctx, cancel := CtxWithIDs(context.Background(), time.Minute)
+	defer cancel()
+	ids := []string{GetTraceID(ctx), GetSpanID(ctx), GetCorrelationID(ctx)}
+	for i, id := range ids {
+		if id == "" {
+			t.Fatalf("ID %d missing from CtxWithIDs context", i)
+		}
+	}
+	if ids[0] == ids[1] || ids[1] == ids[2] || ids[0] == ids[2] {
+		t.Errorf("CtxWithIDs generated duplicate IDs %q", ids)
+	}
+}
+
+func TestWithTraceIDKey(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON), WithTraceIDKey("traceId"))
+	ctx := context.WithValue(context.Background(), TraceIDContextKey, "trace-1")
+	ctx = context.WithValue(ctx, SpanIDContextKey, "span-1")
+
+	logger.InfoCtx(ctx, "request")
+
+	record := decodeJSONLines(t, buf)[0]
+	if record["traceId"] != "trace-1" || record[TraceIDKey] != nil {
+		t.Errorf("record = %v, want the trace ID under traceId only", record)
+	}
+	if record[SpanIDKey] != "span-1" {
+		t.Errorf("record = %v, want the span ID under its default key", record)
+	}
+}
+
+// foreignKey is a context key type of another package that shares the name of the trace ID
+type foreignKey string
+
+func TestTraceIDContextKeys(t *testing.T) {
+	typed := context.WithValue(context.Background(), TraceIDContextKey, "typed")
+	legacy := context.WithValue(context.Background(), TraceIDKey, "legacy")
+	foreign := context.WithValue(context.Background(), foreignKey("trace_id"), "foreign")
+
+	t.Cleanup(func() { SetLegacyStringKeys(false) })
+	for _, enabled := range []bool{false, true} {
+		SetLegacyStringKeys(enabled)
+
+		if got := GetTraceID(typed); got != "typed" {
+			t.Errorf("legacy keys %t: GetTraceID(struct key) = %q, want typed", enabled, got)
+		}
+		if got := GetTraceID(foreign); got != "" {
+			t.Errorf("legacy keys %t: GetTraceID(foreign key) = %q, want none", enabled, got)
+		}
+
+		want := ""
+		if enabled {
+			want = "legacy"
+		}
+		if got := GetTraceID(legacy); got != want {
+			t.Errorf("legacy keys %t: GetTraceID(string key) = %q, want %q", enabled, got, want)
+		}
+	}
+
+	// The typed key takes precedence over the string key
+	both := context.WithValue(legacy, TraceIDContextKey, "typed")
+	if got := GetTraceID(both); got != "typed" {
+		t.Errorf("GetTraceID with both keys = %q, want typed", got)
+	}
+}
+
+func TestTraceIDContextKeysInRecords(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+	ctx := context.WithValue(context.Background(), TraceIDKey, "someone-else")
+
+	logger.InfoCtx(ctx, "foreign value")
+
+	if id, ok := decodeJSONLines(t, buf)[0][TraceIDKey]; ok {
+		t.Errorf("trace_id = %v taken from a string-keyed value", id)
+	}
+}
+
+func TestCtxWithSpan(t *testing.T) {
+	root, cancel := CtxWithTraceID(context.Background(), time.Minute)
+	defer cancel()
+
+	first, cancelFirst := CtxWithSpan(root, time.Minute)
+	defer cancelFirst()
+	second, cancelSecond := CtxWithSpan(root, time.Minute)
+	defer cancelSecond()
+
+	traceID := GetTraceID(root)
+	for name, ctx := range map[string]context.Context{"first": first, "second": second} {
+		if got := GetTraceID(ctx); got != traceID {
+			t.Errorf("%s span trace ID = %q, want parent's %q", name, got, traceID)
+		}
+		if GetSpanID(ctx) == "" {
+			t.Errorf("%s span has no span ID", name)
+		}
+	}
+	if GetSpanID(first) == GetSpanID(second) {
+		t.Errorf("child spans share span ID %q", GetSpanID(first))
+	}
+
+	orphan, cancelOrphan := CtxWithSpan(context.Background(), time.Minute)
+	defer cancelOrphan()
+	if GetTraceID(orphan) == "" || GetSpanID(orphan) == "" {
+		t.Errorf("span without parent trace = trace %q, span %q, want both generated", GetTraceID(orphan), GetSpanID(orphan))
+	}
+}
+
+func TestContextDeadline(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON), WithContextDeadline(true), WithContextDeadlineThreshold(10*time.Second))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	logger.InfoCtx(ctx, "near timeout")
+
+	record := decodeJSONLines(t, buf)[0]
+	remaining, ok := record["deadline_remaining_ms"].(float64)
+	if !ok || remaining <= 0 || remaining > 2000 {
+		t.Errorf("deadline_remaining_ms = %v, want within (0, 2000]", record["deadline_remaining_ms"])
+	}
+}
+
+func TestContextDeadlineThreshold(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		threshold time.Duration
+		want      func(ms float64) bool
+	}{
+		{"beyond threshold", ctx, 5 * time.Second, nil},
+		{"zero threshold", ctx, 0, func(ms float64) bool { return ms > 55000 && ms <= 60000 }},
+		{"expired", expired, 5 * time.Second, func(ms float64) bool { return ms <= -1000 }},
+		{"no deadline", context.Background(), 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, buf := newBufferLogger(WithFormat(FormatJSON), WithContextDeadline(true), WithContextDeadlineThreshold(tt.threshold))
+			logger.InfoCtx(tt.ctx, "probe")
+
+			v, ok := decodeJSONLines(t, buf)[0]["deadline_remaining_ms"].(float64)
+			if tt.want == nil {
+				if ok {
+					t.Errorf("deadline_remaining_ms = %v, want none", v)
+				}
+				return
+			}
+			if !ok || !tt.want(v) {
+				t.Errorf("deadline_remaining_ms = %v (present %t)", v, ok)
+			}
+		})
+	}
+
+	// Without WithContextDeadline the attribute is never added
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+	logger.InfoCtx(expired, "probe")
+	if v, ok := decodeJSONLines(t, buf)[0]["deadline_remaining_ms"]; ok {
+		t.Errorf("deadline_remaining_ms = %v without WithContextDeadline", v)
+	}
+}
+
+func TestRequestIDsFromFastHTTP(t *testing.T) {
+	fhCtx := &fasthttp.RequestCtx{}
+	fhCtx.SetUserValue(CorrelationIDKey, "corr-only")
+	if got := GetCorrelationID(fhCtx); got != "corr-only" {
+		t.Errorf("GetCorrelationID = %q, want corr-only", got)
+	}
+	if got := GetTraceID(fhCtx); got != "" {
+		t.Errorf("GetTraceID = %q, want none", got)
+	}
+
+	fhCtx = &fasthttp.RequestCtx{}
+	RequestIDsToFHCtx(fhCtx)
+	for name, id := range map[string]string{"trace": GetTraceID(fhCtx), "span": GetSpanID(fhCtx), "correlation": GetCorrelationID(fhCtx)} {
+		if id == "" {
+			t.Errorf("%s ID missing after RequestIDsToFHCtx", name)
+		}
+	}
+}
+
+func TestWithPrefix(t *testing.T) {
+	dir := enableTestFileLogging(t, FileLoggerOptions{})
+	logger, buf := newBufferLogger()
+
+	logger.WithPrefix("db").Info("connected")
+	logger.WithPrefix("db").WithPrefix("pool").Warn("exhausted")
+	logger.Info("parent")
+
+	got := lines(buf)
+	if len(got) != 3 {
+		t.Fatalf("got %d console lines, want 3:\n%s", len(got), buf)
+	}
+	for i, want := range []string{"db: connected", "db.pool: exhausted"} {
+		if !strings.Contains(got[i], want) {
+			t.Errorf("record %q does not contain %q", got[i], want)
+		}
+	}
+	if strings.Contains(got[2], "db") {
+		t.Errorf("parent record %q carries the prefix of the child", got[2])
+	}
+
+	file := readLogFiles(t, dir)
+	for _, want := range []string{"INFO | db: connected", "WARN | db.pool: exhausted", "INFO | parent"} {
+		if !strings.Contains(file, want) {
+			t.Errorf("file output does not contain %q:\n%s", want, file)
+		}
+	}
+}
+
+func TestPackageWithPrefix(t *testing.T) {
+	logger, buf := newBufferLogger()
+	useDefaultLogger(t, logger)
+
+	WithPrefix("cache").Info("miss")
+
+	if !strings.Contains(buf.String(), "cache: miss") {
+		t.Errorf("output %q does not contain the prefixed message", buf)
+	}
+}
+
+func TestEnabledFollowsDefaultLevel(t *testing.T) {
+	prevLevel := defaultLevel.Level()
+	t.Cleanup(func() { defaultLevel.Set(prevLevel) })
+	logger, buf := newBufferLogger(WithLevel(&defaultLevel))
+	useDefaultLogger(t, logger)
+	ctx := context.Background()
+
+	SetDefaultLevel(slog.LevelWarn)
+
+	if Enabled(ctx, slog.LevelInfo) {
+		t.Error("Enabled(info) = true after SetDefaultLevel(warn)")
+	}
+	if !Enabled(ctx, slog.LevelError) {
+		t.Error("Enabled(error) = false after SetDefaultLevel(warn)")
+	}
+
+	// Records pass log exactly when Enabled reports their level
+	for _, level := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError} {
+		buf.Reset()
+		logger.LogAttrs(ctx, level, "probe")
+		if written := buf.Len() > 0; written != logger.Enabled(ctx, level) {
+			t.Errorf("level %v: written = %t, Enabled = %t", level, written, logger.Enabled(ctx, level))
+		}
+	}
+}
+
+func TestNewLoggerWithHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLoggerWithHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{AddSource: true}))
+	ctx, cancel := CtxWithTraceID(context.Background(), time.Minute)
+	defer cancel()
+
+	logger.InfoCtx(ctx, "injected", slog.Int("n", 1))
+
+	out := buf.String()
+	for _, want := range []string{"msg=injected", "n=1", "trace_id=" + GetTraceID(ctx)} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not contain %s", out, want)
+		}
+	}
+	// The options of an opaque handler are unknown, so Logger adds no source of its own
+	if strings.Contains(out, " source=[") {
+		t.Errorf("output %q carries a Logger source for an opaque handler", out)
+	}
+
+	buf.Reset()
+	h := NewCustomHandler(&buf, &slog.HandlerOptions{AddSource: true}, true)
+	h.color = false
+	NewLoggerWithHandler(h).Info("custom")
+	if !strings.Contains(buf.String(), "service_test.go:") {
+		t.Errorf("output %q has no source for a CustomHandler with AddSource", buf.String())
+	}
+}
+
+func TestFormatLogEntryTree(t *testing.T) {
+	tests := []struct {
+		name  string
+		style TreeStyle
+		attrs []slog.Attr
+		want  []string
+	}{
+		{"zero attributes", TreeStyleUnicode, nil, nil},
+		{"single attribute", TreeStyleUnicode, []slog.Attr{slog.Int("a", 1)}, []string{"  └─ a: 1"}},
+		{"several attributes", TreeStyleUnicode, []slog.Attr{slog.Int("a", 1), slog.Int("b", 2)}, []string{"  ├─ a: 1", "  └─ b: 2"}},
+		{"ASCII", TreeStyleASCII, []slog.Attr{slog.Int("a", 1), slog.Int("b", 2)}, []string{"  +-- a: 1", "  +-- b: 2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := NewLogger(WithTreeStyle(tt.style))
+			record := slog.NewRecord(time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC), slog.LevelInfo, "entry", 0)
+			record.AddAttrs(tt.attrs...)
+
+			got := strings.Split(logger.formatLogEntry(nil, record), "\n")
+			if len(got) != len(tt.want)+1 || !strings.HasSuffix(got[0], "| entry") {
+				t.Fatalf("entry lines = %q, want the header followed by %q", got, tt.want)
+			}
+			for i, want := range tt.want {
+				if got[i+1] != want {
+					t.Errorf("line %d = %q, want %q", i+1, got[i+1], want)
+				}
+			}
+		})
+	}
+}
+
+// decodeJSONLines decodes every line of buf as a JSON object
+func decodeJSONLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range lines(buf) {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogRequestIDs(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+	ctx, cancel := CtxWithIDs(context.Background(), time.Minute)
+	defer cancel()
+
+	logger.InfoCtx(ctx, "with IDs")
+
+	record := decodeJSONLines(t, buf)[0]
+	for key, want := range map[string]string{
+		TraceIDKey:       GetTraceID(ctx),
+		SpanIDKey:        GetSpanID(ctx),
+		CorrelationIDKey: GetCorrelationID(ctx),
+	} {
+		if record[key] != want {
+			t.Errorf("%s = %v, want %s", key, record[key], want)
+		}
+	}
+}
+
+func TestBufferedFileLogger(t *testing.T) {
+	dir := t.TempDir()
+	fl := newFileLogger(FileLoggerOptions{Dir: dir, BufferSize: 64 << 10, FlushInterval: time.Hour})
+	fl.enable()
+	defer fl.Close()
+
+	fl.writeToFile("buffered")
+	path := filepath.Join(dir, time.Now().Format("2006-01-02")+".log")
+	if data, _ := os.ReadFile(path); len(data) != 0 {
+		t.Fatalf("entry reached the file before Flush: %q", data)
+	}
+
+	if err := fl.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "buffered\n" {
+		t.Fatalf("file after Flush = %q, want the buffered entry", data)
+	}
+
+	fl.writeToFile("closed")
+	if err := fl.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "buffered\nclosed\n" {
+		t.Errorf("file after Close = %q, want both entries", data)
+	}
+}
+
+func TestBufferedFileLoggerPeriodicFlush(t *testing.T) {
+	dir := t.TempDir()
+	fl := newFileLogger(FileLoggerOptions{Dir: dir, BufferSize: 64 << 10, FlushInterval: 10 * time.Millisecond})
+	fl.enable()
+	defer fl.Close()
+
+	fl.writeToFile("flushed in the background")
+	path := filepath.Join(dir, time.Now().Format("2006-01-02")+".log")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if data, _ := os.ReadFile(path); len(data) > 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("buffer was not flushed by the flush interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// benchmarkFileLogger measures writing entries through a file logger configured by opts
+func benchmarkFileLogger(b *testing.B, opts FileLoggerOptions) {
+	opts.Dir = b.TempDir()
+	fl := newFileLogger(opts)
+	fl.enable()
+	defer fl.Close()
+
+	entry := "[2026-01-02 15:04:05.000] INFO  | payment processed\n  └─ amount: 42"
+	b.ReportAllocs()
+	for b.Loop() {
+		fl.writeToFile(entry)
+	}
+}
+
+func BenchmarkFileLoggerUnbuffered(b *testing.B) {
+	benchmarkFileLogger(b, FileLoggerOptions{})
+}
+
+func BenchmarkFileLoggerBuffered(b *testing.B) {
+	benchmarkFileLogger(b, FileLoggerOptions{BufferSize: 64 << 10})
+}
+
+// BenchmarkFileLoggerBufferedSpeedup reports how many times faster buffered writes are than
+// unbuffered ones, failing below the factor of 5 the buffer is meant to provide. Each
+// iteration writes a batch of entries through both loggers in turn
+func BenchmarkFileLoggerBufferedSpeedup(b *testing.B) {
+	unbuffered := newFileLogger(FileLoggerOptions{Dir: b.TempDir()})
+	buffered := newFileLogger(FileLoggerOptions{Dir: b.TempDir(), BufferSize: 64 << 10})
+	var elapsed [2]time.Duration
+	for _, fl := range []*FileLogger{unbuffered, buffered} {
+		fl.enable()
+		defer fl.Close()
+	}
+
+	entry := "[2026-01-02 15:04:05.000] INFO  | payment processed\n  └─ amount: 42"
+	for b.Loop() {
+		for i, fl := range []*FileLogger{unbuffered, buffered} {
+			start := time.Now()
+			for range 1000 {
+				fl.writeToFile(entry)
+			}
+			elapsed[i] += time.Since(start)
+		}
+	}
+
+	speedup := float64(elapsed[0]) / float64(elapsed[1])
+	b.ReportMetric(speedup, "speedup")
+	if speedup < 5 {
+		b.Errorf("buffered writes are %.1f times faster than unbuffered ones, want at least 5", speedup)
+	}
+}
+
+// logThroughWrappers logs msg through two wrapper frames, as a logging facade would
+func logThroughWrappers(l *Logger, msg string) {
+	logThroughWrapper(l, msg)
+}
+
+// logThroughWrapper is the inner wrapper frame of logThroughWrappers
+func logThroughWrapper(l *Logger, msg string) {
+	l.InfoCtx(context.Background(), msg)
+}
+
+// sourceOf returns the source attribute of the single record in buf
+func sourceOf(t *testing.T, buf *bytes.Buffer) string {
+	t.Helper()
+	records := decodeJSONLines(t, buf)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	source, _ := records[0]["source"].(string)
+	return source
+}
+
+func TestCallerSkipOption(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON), WithCallerSkipOption(2))
+
+	_, file, line, _ := runtime.Caller(0)
+	logThroughWrappers(logger, "wrapped")
+
+	if got, want := sourceOf(t, buf), fmt.Sprintf("[%s:%d]", file, line+1); got != want {
+		t.Errorf("source = %s, want %s", got, want)
+	}
+}
+
+func TestWithCallerSkip(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+	logger = logger.WithCallerSkip(1).WithCallerSkip(1)
+
+	_, file, line, _ := runtime.Caller(0)
+	logThroughWrappers(logger, "wrapped")
+
+	if got, want := sourceOf(t, buf), fmt.Sprintf("[%s:%d]", file, line+1); got != want {
+		t.Errorf("source = %s, want %s", got, want)
+	}
+}
+
+func TestSourceOfMethodsAndPackageFunctions(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON), WithExitFunc(func(int) {}), WithPanicFunc(func(string) {}))
+	useDefaultLogger(t, logger)
+
+	calls := []func(){
+		func() { logger.Info("method") },
+		func() { logger.ErrorCtxErr(context.Background(), "method", errors.New("e")) },
+		func() { logger.InfoFunc("method", func() []slog.Attr { return nil }) },
+		func() { Info("function") },
+		func() { WarnCtx(context.Background(), "function") },
+		func() { ErrorCtxErr(context.Background(), "function", errors.New("e")) },
+		func() { InfoFunc("function", func() []slog.Attr { return nil }) },
+		func() { logger.Fatal("method") },
+		func() { FatalCtx(context.Background(), "function") },
+		func() { logger.PanicCtx(context.Background(), "method") },
+		func() { Panic("function") },
+	}
+	for i, call := range calls {
+		buf.Reset()
+		call()
+		if source := sourceOf(t, buf); !strings.Contains(source, "service_test.go") {
+			t.Errorf("call %d attributed to %s, want service_test.go", i, source)
+		}
+	}
+}
+
+func TestFileLoggerStats(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 14, 23, 0, 0, 0, time.UTC)}
+	fl := newTestFileLogger(t, FileLoggerOptions{}, clock)
+
+	if stats := fl.Stats(); stats != (FileLoggerStats{}) {
+		t.Errorf("stats before the first write = %+v, want zero", stats)
+	}
+
+	for i := range 100 {
+		fl.writeToFile(fmt.Sprintf("record %03d", i)) // 11 bytes with the newline
+	}
+
+	stats := fl.Stats()
+	if stats.RecordsWritten != 100 {
+		t.Errorf("RecordsWritten = %d, want 100", stats.RecordsWritten)
+	}
+	if stats.FileSizeBytes != 1100 {
+		t.Errorf("FileSizeBytes = %d, want 1100", stats.FileSizeBytes)
+	}
+	if filepath.Base(stats.CurrentFile) != "2026-03-14.log" || stats.RotationCount != 0 {
+		t.Errorf("stats = %+v, want the first file without rotations", stats)
+	}
+	if !stats.LastWriteTime.Equal(clock.now) {
+		t.Errorf("LastWriteTime = %v, want %v", stats.LastWriteTime, clock.now)
+	}
+
+	clock.now = clock.now.Add(2 * time.Hour)
+	fl.writeToFile("next day")
+
+	stats = fl.Stats()
+	if stats.RotationCount != 1 || stats.RecordsWritten != 1 || filepath.Base(stats.CurrentFile) != "2026-03-15.log" {
+		t.Errorf("stats after rotation = %+v, want one rotation and one record in 2026-03-15.log", stats)
+	}
+}
+
+func TestFileLoggerStatsExternalAppend(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)}
+	fl := newTestFileLogger(t, FileLoggerOptions{}, clock)
+	fl.writeToFile("record")
+
+	f, err := os.OpenFile(fl.Stats().CurrentFile, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("appended elsewhere\n")
+	f.Close()
+
+	if got, want := fl.Stats().FileSizeBytes, int64(len("record\nappended elsewhere\n")); got != want {
+		t.Errorf("FileSizeBytes = %d, want %d including the external append", got, want)
+	}
+}
+
+func TestGetFileLoggerStats(t *testing.T) {
+	dir := enableTestFileLogging(t, FileLoggerOptions{})
+	logger, _ := newBufferLogger()
+
+	for range 3 {
+		logger.Info("record")
+	}
+
+	stats := GetFileLoggerStats()
+	if stats.RecordsWritten != 3 || filepath.Dir(stats.CurrentFile) != dir {
+		t.Errorf("stats = %+v, want 3 records in %s", stats, dir)
+	}
+}
+
+func TestFileLoggingConcurrentReconfiguration(t *testing.T) {
+	dir := enableTestFileLogging(t, FileLoggerOptions{})
+	logger, _ := newBufferLogger()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					logger.Info("concurrent", "n", 1)
+				}
+			}
+		}()
+	}
+
+	for i := range 50 {
+		switch i % 3 {
+		case 0:
+			EnableFileLoggingWithOptions(FileLoggerOptions{Dir: dir, BufferSize: 1024})
+		case 1:
+			DisableFileLogging()
+		case 2:
+			EnableFileLogging()
+		}
+		GetFileLoggerStats()
+	}
+	close(stop)
+	wg.Wait()
+
+	EnableFileLoggingWithOptions(FileLoggerOptions{Dir: dir})
+	logger.Info("after reconfiguration")
+	if got := readLogFiles(t, dir); !strings.Contains(got, "after reconfiguration") {
+		t.Errorf("log files do not contain the record logged after reconfiguration:\n%s", got)
+	}
+}
+
+func TestFileLoggerConcurrentClose(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)}
+	fl := newTestFileLogger(t, FileLoggerOptions{BufferSize: 1024}, clock)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					fl.writeToFile("concurrent")
+				}
+			}
+		}()
+	}
+
+	for range 50 {
+		fl.Close()
+		fl.enable()
+	}
+	close(stop)
+	wg.Wait()
+
+	fl.writeToFile("after reopening")
+	fl.Close()
+	data, err := os.ReadFile(filepath.Join(fl.opts.Dir, "2026-03-14.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(data), "after reopening\n") {
+		t.Errorf("log file does not end with the record written after reopening")
+	}
+}
+
+func TestGoroutineID(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(WithWriter(&buf), WithFormat(FormatJSON), WithGoroutineID(true))
+
+	ids := make([]uint64, 2)
+	var wg sync.WaitGroup
+	for i := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids[i] = goroutineID()
+			logger.Info("from goroutine", slog.Int("i", i))
+		}()
+	}
+	wg.Wait()
+
+	records := decodeJSONLines(t, &buf.Buffer)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if ids[0] == 0 || ids[0] == ids[1] {
+		t.Fatalf("goroutine IDs = %v, want two distinct non-zero IDs", ids)
+	}
+	for _, record := range records {
+		i := int(record["i"].(float64))
+		if got := uint64(record["goroutine_id"].(float64)); got != ids[i] {
+			t.Errorf("record %d has goroutine_id %d, want %d", i, got, ids[i])
+		}
+	}
+}
+
+func TestGoroutineIDMatchesStack(t *testing.T) {
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, want := goroutineID(), stackGoroutineID(); got != want {
+				t.Errorf("goroutineID = %d, want %d from the stack header", got, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkGoroutineID(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		goroutineID()
+	}
+}
+
+// BenchmarkInfoGoroutineID reports the cost WithGoroutineID adds to a full Info call, failing
+// at 500ns or more. Each iteration logs a batch of records with and without the ID in turn
+func BenchmarkInfoGoroutineID(b *testing.B) {
+	loggers := []*Logger{
+		NewLogger(WithWriter(io.Discard), WithColor(false)),
+		NewLogger(WithWriter(io.Discard), WithColor(false), WithGoroutineID(true)),
+	}
+	var elapsed [2]time.Duration
+	const batch = 1000
+	for b.Loop() {
+		for i, logger := range loggers {
+			start := time.Now()
+			for range batch {
+				logger.Info("order matched", slog.Int("id", 42))
+			}
+			elapsed[i] += time.Since(start)
+		}
+	}
+
+	overhead := float64(elapsed[1]-elapsed[0]) / float64(b.N*batch)
+	b.ReportMetric(overhead, "overhead-ns")
+	if overhead >= 500 {
+		b.Errorf("WithGoroutineID adds %.0fns to Info, want less than 500ns", overhead)
+	}
+}
+
+func TestInitLoggerConcurrentWithLogging(t *testing.T) {
+	useDefaultLogger(t, DefaultLogger())
+	prevMin := MinLogger()
+	t.Cleanup(func() { SetMinLogger(prevMin) })
+	prevLevel := defaultLevel.Level()
+	t.Cleanup(func() { defaultLevel.Set(prevLevel) })
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					Info("package-level", "n", 1)
+					ErrorCtxErr(context.Background(), "failed", errors.New("e"))
+					MinLogger().Info("minimal")
+					DefaultLogger().Debug("method")
+				}
+			}
+		}()
+	}
+
+	for i := range 50 {
+		InitLogger(slog.LevelDebug, WithWriter(io.Discard))
+		if i%2 == 0 {
+			SetDefaultLogger(NewLogger(WithWriter(io.Discard)))
+			SetMinLogger(NewLogger(WithWriter(io.Discard), WithSource(false)))
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestSetMinLoggerLeavesMin(t *testing.T) {
+	prev := MinLogger()
+	t.Cleanup(func() { SetMinLogger(prev) })
+
+	before := Min
+	logger := NewLogger(WithWriter(io.Discard))
+	SetMinLogger(logger)
+
+	if MinLogger() != logger {
+		t.Error("MinLogger did not return the logger passed to SetMinLogger")
+	}
+	if Min != before {
+		t.Error("SetMinLogger assigned the deprecated Min variable")
+	}
+}
+
+func TestTimestampFormat(t *testing.T) {
+	unixMilli := func(s string) (time.Time, error) {
+		ms, err := strconv.ParseInt(s, 10, 64)
+		return time.UnixMilli(ms), err
+	}
+	rfc3339 := func(s string) (time.Time, error) { return time.Parse(time.RFC3339, s) }
+
+	tests := []struct {
+		name   string
+		layout string
+		parse  func(string) (time.Time, error)
+	}{
+		{"RFC 3339", time.RFC3339, rfc3339},
+		{"Unix milliseconds", TimestampUnixMilli, unixMilli},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := enableTestFileLogging(t, FileLoggerOptions{})
+			logger, buf := newBufferLogger(WithTimestampFormat(tt.layout))
+			jsonLogger, jsonBuf := newBufferLogger(WithTimestampFormat(tt.layout), WithFormat(FormatJSON))
+
+			before := time.Now().Truncate(time.Second)
+			logger.Info("record")
+			jsonLogger.Info("record")
+
+			console, _, _ := strings.Cut(buf.String(), " ")
+			file, _, _ := strings.Cut(strings.TrimPrefix(readLogFiles(t, dir), "["), "]")
+			var record map[string]json.RawMessage
+			if err := json.Unmarshal(jsonBuf.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+			jsonTime := strings.Trim(string(record["time"]), `"`)
+
+			for output, ts := range map[string]string{"console": console, "file": file, "JSON": jsonTime} {
+				got, err := tt.parse(ts)
+				if err != nil {
+					t.Errorf("%s timestamp %q does not match the format: %v", output, ts, err)
+					continue
+				}
+				if got.Before(before) || got.After(time.Now()) {
+					t.Errorf("%s timestamp %v is not the logging time", output, got)
+				}
+			}
+			if tt.layout == TimestampUnixMilli && record["time"][0] == '"' {
+				t.Errorf("JSON time = %s, want an integer", record["time"])
+			}
+		})
+	}
+}
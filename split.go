@@ -0,0 +1,50 @@
+package sloglog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SplitHandler dispatches records to one of two handlers depending on their level
+type SplitHandler struct {
+	threshold slog.Level
+	low       slog.Handler
+	high      slog.Handler
+}
+
+// NewSplitHandler creates a handler that sends records below threshold to low and all others to high
+func NewSplitHandler(threshold slog.Level, low, high slog.Handler) *SplitHandler {
+	return &SplitHandler{
+		threshold: threshold,
+		low:       low,
+		high:      high,
+	}
+}
+
+// handlerFor returns the handler responsible for the given level
+func (h *SplitHandler) handlerFor(level slog.Level) slog.Handler {
+	if level < h.threshold {
+		return h.low
+	}
+	return h.high
+}
+
+// Enabled reports whether the handler responsible for level handles it
+func (h *SplitHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handlerFor(level).Enabled(ctx, level)
+}
+
+// Handle forwards the Record to the handler responsible for its level
+func (h *SplitHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handlerFor(r.Level).Handle(ctx, r)
+}
+
+// WithAttrs returns a new SplitHandler whose handlers both include attrs
+func (h *SplitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return NewSplitHandler(h.threshold, h.low.WithAttrs(attrs), h.high.WithAttrs(attrs))
+}
+
+// WithGroup returns a new SplitHandler whose handlers both open the given group
+func (h *SplitHandler) WithGroup(name string) slog.Handler {
+	return NewSplitHandler(h.threshold, h.low.WithGroup(name), h.high.WithGroup(name))
+}
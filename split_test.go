@@ -0,0 +1,50 @@
+package sloglog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLevelSplitWriter(t *testing.T) {
+	var low, high bytes.Buffer
+	logger := NewLogger(
+		WithLevel(slog.LevelDebug),
+		WithLevelSplitWriter(slog.LevelWarn, &low, &high),
+	)
+
+	logger.Info("info record")
+	logger.Warn("warn record")
+	logger.Error("error record")
+
+	if !strings.Contains(low.String(), "info record") {
+		t.Errorf("low writer %q is missing the info record", low.String())
+	}
+	for _, msg := range []string{"warn record", "error record"} {
+		if !strings.Contains(high.String(), msg) {
+			t.Errorf("high writer %q is missing %q", high.String(), msg)
+		}
+		if strings.Contains(low.String(), msg) {
+			t.Errorf("low writer %q contains %q", low.String(), msg)
+		}
+	}
+	if strings.Contains(high.String(), "info record") {
+		t.Errorf("high writer %q contains the info record", high.String())
+	}
+}
+
+func TestSplitHandlerWithAttrs(t *testing.T) {
+	var low, high bytes.Buffer
+	logger := NewLogger(WithLevelSplitWriter(slog.LevelError, &low, &high))
+
+	child := logger.With(slog.String("svc", "payments"))
+	child.Info("low")
+	child.Error("high")
+
+	for name, buf := range map[string]*bytes.Buffer{"low": &low, "high": &high} {
+		if !strings.Contains(buf.String(), "svc=payments") {
+			t.Errorf("%s writer %q is missing svc=payments", name, buf.String())
+		}
+	}
+}
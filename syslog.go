@@ -0,0 +1,281 @@
+//go:build !windows && !plan9
+
+package sloglog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"log/syslog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reconnection settings for SyslogHandler
+const (
+	syslogInitialBackoff = 100 * time.Millisecond
+	syslogMaxBackoff     = 10 * time.Second
+
+	// syslogPendingLimit is the number of records queued while reconnecting
+	syslogPendingLimit = 1024
+)
+
+// errSyslogDropped is returned for records dropped because the reconnection queue is full
+var errSyslogDropped = errors.New("syslog connection lost and reconnection queue full, record dropped")
+
+// SyslogHandler implements slog.Handler by writing records to a syslog daemon
+type SyslogHandler struct {
+	opts slog.HandlerOptions
+	conn *syslogConn
+
+	// groupStack holds the open groups, outermost first
+	groupStack []string
+	// attrs[i] holds the attributes added while i groups were open
+	attrs [][]slog.Attr
+}
+
+// syslogConn is the connection shared by a SyslogHandler and the handlers derived from it
+type syslogConn struct {
+	dial func() (syslogWriter, error)
+
+	mu           sync.Mutex
+	writer       syslogWriter    // nil while reconnecting
+	pending      []syslogMessage // records queued while reconnecting, oldest first
+	reconnecting bool
+	closed       chan struct{} // closed by Close to stop reconnecting
+}
+
+// syslogWriter is the subset of *syslog.Writer used by syslogConn
+type syslogWriter interface {
+	Crit(m string) error
+	Err(m string) error
+	Warning(m string) error
+	Info(m string) error
+	Debug(m string) error
+	Close() error
+}
+
+// syslogMessage is a record queued while reconnecting
+type syslogMessage struct {
+	level slog.Level
+	msg   string
+}
+
+// NewSyslogHandler creates a handler writing to the syslog daemon at addr over network.
+// When both are empty the local syslog socket is used. priority supplies the facility;
+// the severity of each message is derived from the record level
+func NewSyslogHandler(network, addr string, priority int, tag string, opts *slog.HandlerOptions) (slog.Handler, error) {
+	if opts == nil {
+		opts = &slog.HandlerOptions{}
+	}
+
+	conn := newSyslogConn(func() (syslogWriter, error) {
+		return syslog.Dial(network, addr, syslog.Priority(priority), tag)
+	})
+	if err := conn.connect(); err != nil {
+		return nil, err
+	}
+
+	return &SyslogHandler{
+		opts: *opts,
+		conn: conn,
+	}, nil
+}
+
+// Enabled reports whether the handler handles records at the given level
+func (h *SyslogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// Handle writes the Record as "msg key=value ..." at the syslog severity matching its level
+func (h *SyslogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.Enabled(ctx, r.Level) {
+		return nil
+	}
+
+	parts := []string{r.Message}
+	for i, groupAttrs := range h.attrs {
+		prefix := groupPrefix(h.groupStack[:i])
+		for _, a := range groupAttrs {
+			parts = appendTextAttr(parts, prefix, a)
+		}
+	}
+	prefix := groupPrefix(h.groupStack)
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "source" {
+			if h.opts.AddSource {
+				parts = appendTextAttr(parts, "", a)
+			}
+			return true
+		}
+		parts = appendTextAttr(parts, prefix, a)
+		return true
+	})
+
+	return h.conn.write(r.Level, strings.Join(parts, " "))
+}
+
+// WithAttrs returns a new SyslogHandler whose attributes consist of h's attributes followed by attrs
+func (h *SyslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	depth := len(h.groupStack)
+	h2.attrs = make([][]slog.Attr, max(len(h.attrs), depth+1))
+	copy(h2.attrs, h.attrs)
+	current := h2.attrs[depth]
+	h2.attrs[depth] = append(current[:len(current):len(current)], attrs...)
+	return &h2
+}
+
+// WithGroup returns a new SyslogHandler with the given group appended to h's groups
+func (h *SyslogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groupStack = append(h.groupStack[:len(h.groupStack):len(h.groupStack)], name)
+	return &h2
+}
+
+// Close closes the connection shared with the handlers derived from h and stops reconnecting.
+// Records queued while reconnecting are discarded
+func (h *SyslogHandler) Close() error {
+	return h.conn.close()
+}
+
+// newSyslogConn creates a disconnected syslogConn opening connections with dial
+func newSyslogConn(dial func() (syslogWriter, error)) *syslogConn {
+	return &syslogConn{dial: dial, closed: make(chan struct{})}
+}
+
+// connect opens the initial connection to the syslog daemon
+func (c *syslogConn) connect() error {
+	w, err := c.dial()
+	if err != nil {
+		return err
+	}
+	c.writer = w
+	return nil
+}
+
+// write sends msg at the severity matching level. When the connection has dropped, msg is
+// queued and a background goroutine reconnects with exponential back-off, so that logging
+// never waits for the daemon. Records are dropped while the queue is full
+func (c *syslogConn) write(level slog.Level, msg string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.writer != nil {
+		err := writeSyslog(c.writer, level, msg)
+		if err == nil {
+			return nil
+		}
+		c.writer.Close()
+		c.writer = nil
+	}
+
+	select {
+	case <-c.closed:
+		return errors.New("syslog handler is closed")
+	default:
+	}
+
+	if !c.reconnecting {
+		c.reconnecting = true
+		go c.reconnect()
+	}
+	if len(c.pending) >= syslogPendingLimit {
+		return errSyslogDropped
+	}
+	c.pending = append(c.pending, syslogMessage{level: level, msg: msg})
+	return nil
+}
+
+// reconnect dials until a connection succeeds or the handler is closed, then writes the
+// queued records. c.mu is not held while dialing or waiting
+func (c *syslogConn) reconnect() {
+	backoff := syslogInitialBackoff
+	for {
+		if w, err := c.dial(); err == nil && c.resume(w) {
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-c.closed:
+			return
+		}
+		backoff = min(backoff*2, syslogMaxBackoff)
+	}
+}
+
+// resume writes the queued records to w and makes it the current writer. It reports false,
+// closing w, if a write fails; the records not yet written stay queued
+func (c *syslogConn) resume(w syslogWriter) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.closed:
+		w.Close()
+		return true
+	default:
+	}
+
+	for len(c.pending) > 0 {
+		m := c.pending[0]
+		if err := writeSyslog(w, m.level, m.msg); err != nil {
+			w.Close()
+			return false
+		}
+		c.pending = c.pending[1:]
+	}
+	c.pending = nil
+	c.writer = w
+	c.reconnecting = false
+	return true
+}
+
+// close closes the current connection and stops reconnecting
+func (c *syslogConn) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.closed:
+		return nil
+	default:
+	}
+	close(c.closed)
+	c.pending = nil
+
+	if c.writer == nil {
+		return nil
+	}
+	err := c.writer.Close()
+	c.writer = nil
+	return err
+}
+
+// writeSyslog writes msg to w at the syslog severity matching level
+func writeSyslog(w syslogWriter, level slog.Level, msg string) error {
+	switch {
+	case level >= LevelFatal:
+		return w.Crit(msg)
+	case level >= slog.LevelError:
+		return w.Err(msg)
+	case level >= slog.LevelWarn:
+		return w.Warning(msg)
+	case level >= slog.LevelInfo:
+		return w.Info(msg)
+	default:
+		return w.Debug(msg)
+	}
+}
@@ -0,0 +1,194 @@
+//go:build !windows && !plan9
+
+package sloglog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"log/syslog"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newUDPSyslogHandler returns a handler writing to a UDP listener, with a function reading the
+// next packet from the listener
+func newUDPSyslogHandler(t *testing.T) (slog.Handler, func() string) {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on UDP: %v", err)
+	}
+	t.Cleanup(func() { pc.Close() })
+
+	h, err := NewSyslogHandler("udp", pc.LocalAddr().String(), int(syslog.LOG_LOCAL0), "sloglog-test", &slog.HandlerOptions{Level: slog.LevelDebug})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read := func() string {
+		t.Helper()
+		pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+		buf := make([]byte, 2048)
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf[:n])
+	}
+	return h, read
+}
+
+func TestSyslogHandlerUDP(t *testing.T) {
+	h, read := newUDPSyslogHandler(t)
+
+	slog.New(h).With("service", "api").WithGroup("req").Warn("slow request", "ms", 250)
+	packet := read()
+
+	// LOG_LOCAL0 (16<<3) | LOG_WARNING (4)
+	if !strings.HasPrefix(packet, "<132>") {
+		t.Errorf("packet %q does not start with priority <132>", packet)
+	}
+	if !strings.Contains(packet, "sloglog-test[") || !strings.Contains(packet, "slow request service=api req.ms=250") {
+		t.Errorf("packet %q is missing the tag or the formatted record", packet)
+	}
+}
+
+func TestSyslogSeverities(t *testing.T) {
+	h, read := newUDPSyslogHandler(t)
+	logger := slog.New(h)
+
+	// LOG_LOCAL0 (16<<3) | severity
+	tests := []struct {
+		level    slog.Level
+		priority string
+	}{
+		{slog.LevelDebug, "<135>"},
+		{slog.LevelInfo, "<134>"},
+		{slog.LevelWarn, "<132>"},
+		{slog.LevelError, "<131>"},
+		{LevelFatal, "<130>"},
+	}
+	for _, tt := range tests {
+		logger.Log(context.Background(), tt.level, "record")
+		if packet := read(); !strings.HasPrefix(packet, tt.priority) {
+			t.Errorf("%v record sent as %q, want priority %s", tt.level, packet, tt.priority)
+		}
+	}
+}
+
+// fakeSyslogWriter records messages as "severity: message" and fails writes while failing is set
+type fakeSyslogWriter struct {
+	mu       sync.Mutex
+	messages []string
+	failing  bool
+}
+
+func (w *fakeSyslogWriter) write(severity, m string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failing {
+		return errors.New("connection reset")
+	}
+	w.messages = append(w.messages, severity+": "+m)
+	return nil
+}
+
+func (w *fakeSyslogWriter) Crit(m string) error    { return w.write("crit", m) }
+func (w *fakeSyslogWriter) Err(m string) error     { return w.write("err", m) }
+func (w *fakeSyslogWriter) Warning(m string) error { return w.write("warning", m) }
+func (w *fakeSyslogWriter) Info(m string) error    { return w.write("info", m) }
+func (w *fakeSyslogWriter) Debug(m string) error   { return w.write("debug", m) }
+func (w *fakeSyslogWriter) Close() error           { return nil }
+
+func (w *fakeSyslogWriter) Messages() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.messages)
+}
+
+// newFakeSyslogHandler returns a handler whose connections are opened by dial
+func newFakeSyslogHandler(t *testing.T, dial func() (syslogWriter, error)) *SyslogHandler {
+	t.Helper()
+	conn := newSyslogConn(dial)
+	if err := conn.connect(); err != nil {
+		t.Fatal(err)
+	}
+	h := &SyslogHandler{opts: slog.HandlerOptions{Level: slog.LevelDebug}, conn: conn}
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+func TestSyslogReconnect(t *testing.T) {
+	first := &fakeSyslogWriter{}
+	second := &fakeSyslogWriter{}
+
+	var mu sync.Mutex
+	dials := 0
+	dial := func() (syslogWriter, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		dials++
+		switch dials {
+		case 1:
+			return first, nil
+		case 2:
+			return nil, errors.New("connection refused")
+		default:
+			return second, nil
+		}
+	}
+	logger := slog.New(newFakeSyslogHandler(t, dial)).With("service", "api")
+
+	logger.Info("before")
+	first.mu.Lock()
+	first.failing = true
+	first.mu.Unlock()
+
+	start := time.Now()
+	logger.Info("lost connection")
+	logger.Warn("while reconnecting")
+	if elapsed := time.Since(start); elapsed >= syslogInitialBackoff {
+		t.Errorf("logging while reconnecting took %v, want it not to wait for the back-off", elapsed)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(second.Messages()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	logger.Info("after")
+
+	if got, want := first.Messages(), []string{"info: before service=api"}; !slices.Equal(got, want) {
+		t.Errorf("first connection messages = %q, want %q", got, want)
+	}
+	want := []string{"info: lost connection service=api", "warning: while reconnecting service=api", "info: after service=api"}
+	if got := second.Messages(); !slices.Equal(got, want) {
+		t.Errorf("messages after reconnecting = %q, want the queued records in order, then %q", got, want[2])
+	}
+}
+
+func TestSyslogQueueFull(t *testing.T) {
+	w := &fakeSyslogWriter{failing: true}
+	connected := false
+	h := newFakeSyslogHandler(t, func() (syslogWriter, error) {
+		if connected {
+			return nil, errors.New("connection refused")
+		}
+		connected = true
+		return w, nil
+	})
+
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "record", 0)
+	for i := range syslogPendingLimit {
+		if err := h.Handle(context.Background(), record); err != nil {
+			t.Fatalf("Handle of record %d failed: %v", i, err)
+		}
+	}
+	if err := h.Handle(context.Background(), record); !errors.Is(err, errSyslogDropped) {
+		t.Errorf("Handle with a full queue = %v, want errSyslogDropped", err)
+	}
+}
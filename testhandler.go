@@ -0,0 +1,151 @@
+package sloglog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// TestHandler is a slog.Handler that captures records in memory so tests can
+// assert on them without capturing os.Stdout.
+//
+// TestHandler keeps every record until Reset is called and is not suitable for production use
+type TestHandler struct {
+	store  *testStore
+	attrs  []slog.Attr
+	groups []string
+}
+
+// testStore holds the records captured by a TestHandler and all handlers derived from it
+type testStore struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+// NewTestHandler creates a handler that captures records at every level
+func NewTestHandler() *TestHandler {
+	return &TestHandler{store: &testStore{}}
+}
+
+// TestingT is the part of testing.TB used by NewTestLogger. Taking it instead of testing.TB keeps
+// the testing package, and its command-line flags, out of programs importing sloglog
+type TestingT interface {
+	Helper()
+	Cleanup(func())
+}
+
+// NewTestLogger creates a logger backed by a TestHandler, which is reset when the test finishes.
+// t is usually a *testing.T or *testing.B
+func NewTestLogger(t TestingT) (*Logger, *TestHandler) {
+	t.Helper()
+	h := NewTestHandler()
+	t.Cleanup(h.Reset)
+	return newLogger(h, defaultLoggerConfig()), h
+}
+
+// Enabled reports true for every level so that all records are captured
+func (h *TestHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+// Handle captures the Record together with the handler's attributes and groups.
+// The source stays at the top level like in the output of CustomHandler
+func (h *TestHandler) Handle(ctx context.Context, r slog.Record) error {
+	var topLevel, attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "source" {
+			topLevel = append(topLevel, a)
+		} else {
+			attrs = append(attrs, a)
+		}
+		return true
+	})
+
+	record := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	record.AddAttrs(topLevel...)
+	record.AddAttrs(h.attrs...)
+	record.AddAttrs(h.grouped(attrs)...)
+
+	h.store.mu.Lock()
+	defer h.store.mu.Unlock()
+	h.store.records = append(h.store.records, record)
+	return nil
+}
+
+// WithAttrs returns a new TestHandler whose captured records also include attrs
+func (h *TestHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = append(h.attrs[:len(h.attrs):len(h.attrs)], h.grouped(attrs)...)
+	return &h2
+}
+
+// WithGroup returns a new TestHandler that nests subsequent attributes under name
+func (h *TestHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &h2
+}
+
+// grouped nests attrs under the handler's open groups
+func (h *TestHandler) grouped(attrs []slog.Attr) []slog.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		args := make([]any, len(attrs))
+		for j, a := range attrs {
+			args[j] = a
+		}
+		attrs = []slog.Attr{slog.Group(h.groups[i], args...)}
+	}
+	return attrs
+}
+
+// Records returns a copy of all captured records in the order they were handled
+func (h *TestHandler) Records() []slog.Record {
+	h.store.mu.Lock()
+	defer h.store.mu.Unlock()
+
+	records := make([]slog.Record, len(h.store.records))
+	for i, r := range h.store.records {
+		records[i] = r.Clone()
+	}
+	return records
+}
+
+// RecordsAtLevel returns the captured records with the given level
+func (h *TestHandler) RecordsAtLevel(level slog.Level) []slog.Record {
+	var records []slog.Record
+	for _, r := range h.Records() {
+		if r.Level == level {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// Contains reports whether a record with the given message has been captured
+func (h *TestHandler) Contains(msg string) bool {
+	h.store.mu.Lock()
+	defer h.store.mu.Unlock()
+
+	for _, r := range h.store.records {
+		if r.Message == msg {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset discards all captured records
+func (h *TestHandler) Reset() {
+	h.store.mu.Lock()
+	defer h.store.mu.Unlock()
+	h.store.records = nil
+}
@@ -0,0 +1,96 @@
+package sloglog
+
+import (
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+func TestTestHandler(t *testing.T) {
+	logger, h := NewTestLogger(t)
+
+	logger.Info("payment processed", slog.Int("amount", 42))
+	logger.Error("payment failed")
+	logger.Debug("debug records are captured too")
+
+	if !h.Contains("payment processed") {
+		t.Error("Contains(payment processed) = false")
+	}
+	if h.Contains("missing") {
+		t.Error("Contains(missing) = true")
+	}
+	if n := len(h.Records()); n != 3 {
+		t.Errorf("got %d records, want 3", n)
+	}
+
+	errs := h.RecordsAtLevel(slog.LevelError)
+	if len(errs) != 1 || errs[0].Message != "payment failed" {
+		t.Errorf("RecordsAtLevel(ERROR) = %v, want the failed payment", errs)
+	}
+
+	h.Reset()
+	if n := len(h.Records()); n != 0 {
+		t.Errorf("got %d records after Reset, want 0", n)
+	}
+}
+
+func TestTestHandlerAttrsAndGroups(t *testing.T) {
+	logger, h := NewTestLogger(t)
+
+	logger.With(slog.String("svc", "payments")).Group("db").Info("query", slog.Int("rows", 3))
+
+	records := h.Records()
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	attrs := map[string]slog.Value{}
+	records[0].Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value
+		return true
+	})
+
+	if got := attrs["svc"].String(); got != "payments" {
+		t.Errorf("svc = %q, want payments", got)
+	}
+	db := attrs["db"]
+	if db.Kind() != slog.KindGroup {
+		t.Fatalf("db = %v, want a group", db)
+	}
+	group := db.Group()
+	if len(group) != 1 || group[0].Key != "rows" || group[0].Value.Int64() != 3 {
+		t.Errorf("db group = %v, want rows=3", group)
+	}
+}
+
+func TestTestHandlerConcurrent(t *testing.T) {
+	logger, h := NewTestLogger(t)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				logger.Info("record")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := len(h.Records()); n != 800 {
+		t.Errorf("got %d records, want 800", n)
+	}
+}
+
+func TestNewTestLoggerResetsOnCleanup(t *testing.T) {
+	var h *TestHandler
+	t.Run("log", func(t *testing.T) {
+		var logger *Logger
+		logger, h = NewTestLogger(t)
+		logger.Info("record")
+	})
+
+	if n := len(h.Records()); n != 0 {
+		t.Errorf("got %d records after the test finished, want 0", n)
+	}
+}
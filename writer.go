@@ -0,0 +1,95 @@
+package sloglog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// levelWriter logs every line written to it at a fixed level
+type levelWriter struct {
+	logger *Logger
+	level  slog.Level
+}
+
+// NewLevelWriter returns a writer that logs each line written to it as a record at level, for
+// libraries that only accept an io.Writer, e.g. log.New(sloglog.NewLevelWriter(logger, slog.LevelWarn), "", 0).
+// Each Write is expected to hold complete lines; empty lines are skipped
+func NewLevelWriter(logger *Logger, level slog.Level) io.Writer {
+	return &levelWriter{logger: logger, level: level}
+}
+
+// Write logs each line of p at the level of the writer
+func (w *levelWriter) Write(p []byte) (int, error) {
+	for _, line := range splitLines(p) {
+		w.logger.log(context.Background(), 2, w.level, line)
+	}
+	return len(p), nil
+}
+
+// AutoLevelWriter is a writer that logs each line written to it at the level named at its start
+type AutoLevelWriter struct {
+	logger *Logger
+}
+
+// NewAutoLevelWriter returns a writer that logs each line written to it at the level detected
+// from its prefix, e.g. "ERROR ..." or "[WARN] ...", and at INFO when there is none.
+// Each Write is expected to hold complete lines; empty lines are skipped
+func NewAutoLevelWriter(logger *Logger) *AutoLevelWriter {
+	return &AutoLevelWriter{logger: logger}
+}
+
+// Write logs each line of p at its detected level
+func (w *AutoLevelWriter) Write(p []byte) (int, error) {
+	for _, line := range splitLines(p) {
+		w.logger.log(context.Background(), 2, detectLevel(line), line)
+	}
+	return len(p), nil
+}
+
+// levelPrefixes maps line prefixes to levels, longer prefixes of the same level first
+var levelPrefixes = []struct {
+	prefix string
+	level  slog.Level
+}{
+	{"DEBUG", slog.LevelDebug},
+	{"INFO", slog.LevelInfo},
+	{"WARNING", slog.LevelWarn},
+	{"WARN", slog.LevelWarn},
+	{"ERROR", slog.LevelError},
+	{"PANIC", LevelPanic},
+	{"FATAL", LevelFatal},
+}
+
+// detectLevel returns the level named at the start of line, ignoring case and an opening
+// bracket, or INFO if there is none. The name must end the word, so "INFORMAL" or "Errors"
+// name no level
+func detectLevel(line string) slog.Level {
+	s := strings.ToUpper(strings.TrimLeft(line, " \t["))
+	for _, p := range levelPrefixes {
+		rest, ok := strings.CutPrefix(s, p.prefix)
+		if !ok {
+			continue
+		}
+		if r, _ := utf8.DecodeRuneInString(rest); rest == "" || !unicode.IsLetter(r) {
+			return p.level
+		}
+	}
+	return slog.LevelInfo
+}
+
+// splitLines returns the non-empty lines of p without line endings
+func splitLines(p []byte) []string {
+	var lines []string
+	for _, line := range bytes.Split(p, []byte("\n")) {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if len(bytes.TrimSpace(line)) > 0 {
+			lines = append(lines, string(line))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,91 @@
+package sloglog
+
+import (
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLevelWriter(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+	w := NewLevelWriter(logger, slog.LevelWarn)
+
+	p := []byte("first\r\nsecond\n\n  \nthird\n")
+	if n, err := w.Write(p); n != len(p) || err != nil {
+		t.Fatalf("Write = %d, %v, want %d, nil", n, err, len(p))
+	}
+
+	records := decodeJSONLines(t, buf)
+	want := []string{"first", "second", "third"}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want one per non-empty line %q", len(records), want)
+	}
+	for i, record := range records {
+		if record["msg"] != want[i] || record["level"] != "WARN" {
+			t.Errorf("record %d = %v, want WARN %q", i, record, want[i])
+		}
+	}
+}
+
+func TestLevelWriterWithStdLogger(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+	log.New(NewLevelWriter(logger, slog.LevelError), "driver: ", 0).Print("connection reset")
+
+	records := decodeJSONLines(t, buf)
+	if len(records) != 1 || records[0]["msg"] != "driver: connection reset" || records[0]["level"] != "ERROR" {
+		t.Errorf("records = %v, want one ERROR record", records)
+	}
+}
+
+func TestAutoLevelWriter(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON), WithLevel(slog.LevelDebug))
+	w := NewAutoLevelWriter(logger)
+
+	w.Write([]byte("ERROR disk failed\n[warn] slow query\nWARNING: retrying\ndebug: cache miss\nlistening on :8080\n"))
+
+	want := []slog.Level{slog.LevelError, slog.LevelWarn, slog.LevelWarn, slog.LevelDebug, slog.LevelInfo}
+	records := decodeJSONLines(t, buf)
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i, record := range records {
+		if record["level"] != want[i].String() {
+			t.Errorf("record %q level = %v, want %v", record["msg"], record["level"], want[i])
+		}
+	}
+}
+
+func TestDetectLevel(t *testing.T) {
+	tests := []struct {
+		line string
+		want slog.Level
+	}{
+		{"ERROR disk failed", slog.LevelError},
+		{"[warn] slow query", slog.LevelWarn},
+		{"WARNING: retrying", slog.LevelWarn},
+		{"  debug: cache miss", slog.LevelDebug},
+		{"FATAL", LevelFatal},
+		{"INFORMAL greeting sent", slog.LevelInfo},
+		{"Errors were found in 0 files", slog.LevelInfo},
+		{"Debugger attached", slog.LevelInfo},
+		{"warned twice", slog.LevelInfo},
+		{"listening on :8080", slog.LevelInfo},
+	}
+
+	for _, tt := range tests {
+		if got := detectLevel(tt.line); got != tt.want {
+			t.Errorf("detectLevel(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestLevelWriterSource(t *testing.T) {
+	logger, buf := newBufferLogger(WithFormat(FormatJSON))
+	NewLevelWriter(logger, slog.LevelInfo).Write([]byte("hello\n"))
+
+	// The record is attributed to the caller of Write
+	if source := sourceOf(t, buf); !strings.Contains(source, "writer_test.go:") {
+		t.Errorf("source = %q, want the call of Write", source)
+	}
+}